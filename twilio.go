@@ -10,13 +10,21 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ajg/form"
 	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/anonymize"
+	"github.com/matthxwpavin/twilio-compositions/video/archive"
 	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/ffmpeg"
+	"github.com/matthxwpavin/twilio-compositions/video/media"
 	"github.com/matthxwpavin/twilio-compositions/video/participants"
+	"github.com/matthxwpavin/twilio-compositions/video/placement"
 	"github.com/matthxwpavin/twilio-compositions/video/recording"
+	"github.com/matthxwpavin/twilio-compositions/video/retention"
 	"github.com/matthxwpavin/twilio-compositions/video/rooms"
+	"github.com/matthxwpavin/twilio-compositions/video/timeline"
 	"github.com/spf13/viper"
 )
 
@@ -39,6 +47,7 @@ type Twilio struct {
 	cred    *Credential
 	baseUrl video.VideoUrl
 	client  *http.Client
+	fetcher media.Fetcher
 }
 
 func New(credential *Credential) *Twilio {
@@ -106,6 +115,19 @@ func (t *Twilio) UpdateCompositionHooks(
 	)
 }
 
+// DiffCompositionHooks reports what UpdateCompositionHooks would change
+// on the composition hooks, without updating it.
+func (t *Twilio) DiffCompositionHooks(
+	hooksSid string,
+	param *composition.HooksParams,
+) (*composition.ParamsDiff, error) {
+	hooks, err := t.GetCompositionHooks(hooksSid)
+	if err != nil {
+		return nil, err
+	}
+	return composition.DiffHooksParams(hooks.ToParams(), param), nil
+}
+
 func (t *Twilio) requestCompositionHooks(
 	method, url string,
 	param *composition.HooksParams,
@@ -136,11 +158,40 @@ func (t *Twilio) requestCompositionHooks(
 	return ret, nil
 }
 
-func (t *Twilio) ListEnabledCompositionHooks() (*composition.CompositionHooksList, error) {
+func (t *Twilio) GetCompositionHooks(hooksSid string) (*composition.CompositionHooks, error) {
+	if hooksSid == "" {
+		return nil, errors.New("Hooks SID must not be empty")
+	}
+
+	ret := &composition.CompositionHooks{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithCompositionHooksURIAndPathParam(hooksSid),
+		"",
+		nil,
+		nil,
+		ret,
+	); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (t *Twilio) ListCompositionHooks(
+	param *composition.HooksGetParams,
+) (*composition.CompositionHooksList, error) {
+	if param == nil {
+		param = &composition.HooksGetParams{}
+	}
+	values, err := form.EncodeToValues(param)
+	if err != nil {
+		return nil, err
+	}
+
 	ret := &composition.CompositionHooksList{}
 	if err := t.request(
 		http.MethodGet,
-		t.baseUrl.WithCompositionHooksURI(),
+		t.baseUrl.WithCompositionHooksURIAndQueryParameters(values),
 		"",
 		nil,
 		nil,
@@ -148,10 +199,51 @@ func (t *Twilio) ListEnabledCompositionHooks() (*composition.CompositionHooksLis
 	); err != nil {
 		return nil, err
 	}
+	return ret, nil
+}
 
+// NextCompositionHooksPage fetches the page after the given one.
+// It returns nil without error when the given page is the last one.
+func (t *Twilio) NextCompositionHooksPage(
+	page *composition.CompositionHooksList,
+) (*composition.CompositionHooksList, error) {
+	ret := &composition.CompositionHooksList{}
+	if ok, err := t.nextPage(page.Meta.NextPageUrl, ret); !ok {
+		return nil, err
+	}
 	return ret, nil
 }
 
+func (t *Twilio) ListEnabledCompositionHooks() (*composition.CompositionHooksList, error) {
+	enabled := true
+	return t.ListCompositionHooks(&composition.HooksGetParams{Enabled: &enabled})
+}
+
+// EnableCompositionHooks turns the composition hooks on, keeping its video layout and other properties.
+func (t *Twilio) EnableCompositionHooks(hooksSid string) (*composition.CompositionHooks, error) {
+	return t.setCompositionHooksEnabled(hooksSid, true)
+}
+
+// DisableCompositionHooks turns the composition hooks off, keeping its video layout and other properties.
+func (t *Twilio) DisableCompositionHooks(hooksSid string) (*composition.CompositionHooks, error) {
+	return t.setCompositionHooksEnabled(hooksSid, false)
+}
+
+func (t *Twilio) setCompositionHooksEnabled(
+	hooksSid string,
+	enabled bool,
+) (*composition.CompositionHooks, error) {
+	// An update replaces the hooks, the properties it leaves out are reset,
+	// so the current hooks are sent back with only Enabled changed.
+	hooks, err := t.GetCompositionHooks(hooksSid)
+	if err != nil {
+		return nil, err
+	}
+	params := hooks.ToParams()
+	params.Enabled = &enabled
+	return t.UpdateCompositionHooks(hooksSid, params)
+}
+
 func (t *Twilio) DeleteCompositionHooks(hooksSid string) error {
 	return t.request(
 		http.MethodDelete,
@@ -206,30 +298,15 @@ func (t *Twilio) ListRoomCompletedCompositions(
 	return ret, nil
 }
 
-type RecordingFilter struct {
-	MediaType      string
-	RoomSid        string
-	ParticipantSid string
-}
-
-func (t *Twilio) ListRecordings(
-	filter RecordingFilter,
-) (*recording.RecordingList, error) {
-	params := url.Values{}
-	if filter.RoomSid != "" {
-		params.Add("GroupingSid", filter.RoomSid)
-	}
-	if filter.ParticipantSid != "" {
-		params.Add("GroupingSid", filter.ParticipantSid)
-	}
-	if filter.MediaType != "" {
-		params.Set("MediaType", filter.MediaType)
+func (t *Twilio) GetComposition(compositionSid string) (*composition.Composition, error) {
+	if compositionSid == "" {
+		return nil, errors.New("Composition SID must not be empty")
 	}
 
-	dst := &recording.RecordingList{}
+	dst := &composition.Composition{}
 	if err := t.request(
 		http.MethodGet,
-		t.baseUrl.WithRecordingsURIAndQueryParam(params),
+		t.baseUrl.WithCompositionURIAndPathParam(compositionSid),
 		"",
 		nil,
 		nil,
@@ -240,77 +317,144 @@ func (t *Twilio) ListRecordings(
 	return dst, nil
 }
 
-func (t *Twilio) GetRecordingMedia(recordingSid string) (*recording.Media, error) {
-	dst := &recording.Media{}
-	return dst, t.request(
-		http.MethodGet,
-		t.baseUrl.WithRecordingsURI()+fmt.Sprintf("/%s/Media", recordingSid),
-		"",
-		nil,
-		func(status int) bool { return status == http.StatusFound },
-		dst,
-	)
+// NextCompositionsPage fetches the page after the given one.
+// It returns nil without error when the given page is the last one.
+func (t *Twilio) NextCompositionsPage(page *composition.CompositionList) (*composition.CompositionList, error) {
+	dst := &composition.CompositionList{}
+	if ok, err := t.nextPage(page.Meta.NextPageUrl, dst); !ok {
+		return nil, err
+	}
+	return dst, nil
 }
 
-func (t *Twilio) GetCompositionMedia(comSid string) (*composition.Composition, error) {
-	ret := &composition.Composition{}
-	if err := t.request(
-		http.MethodGet,
-		t.baseUrl.WithCompositionURIMedia(comSid),
+// DeleteComposition deletes the composition's media. Its metadata stays with the deleted status.
+func (t *Twilio) DeleteComposition(compositionSid string) error {
+	if compositionSid == "" {
+		return errors.New("Composition SID must not be empty")
+	}
+	return t.request(
+		http.MethodDelete,
+		t.baseUrl.WithCompositionURIAndPathParam(compositionSid),
 		"",
 		nil,
 		nil,
-		ret,
-	); err != nil {
-		return nil, err
-	}
-	return ret, nil
+		nil,
+	)
 }
 
-func (t *Twilio) fireWithAuth(req *http.Request, checkStatus func(int) bool) ([]byte, error) {
-	req.SetBasicAuth(t.cred.ApiKeySid, t.cred.ApiKeySecret)
-	resp, err := t.client.Do(req)
+// PlanRetention evaluates the policy over every composition and recording of the account, page by page.
+// Rooms are matched by unique name too, looked up with GetRoomInstance unless opts.RoomName is set.
+// A room Twilio no longer returns has no unique name.
+func (t *Twilio) PlanRetention(
+	ctx context.Context,
+	policy *retention.Policy,
+	opts retention.PlannerOptions,
+) (*retention.Plan, error) {
+	if opts.RoomName == nil {
+		opts.RoomName = func(roomSid string) (string, error) {
+			room, err := t.GetRoomInstance(roomSid)
+			var statusErr *StatusError
+			if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+				// Twilio forgets rooms long before their media, which is then matched by SID only.
+				return "", nil
+			}
+			if err != nil {
+				return "", err
+			}
+			return room.UniqueName, nil
+		}
+	}
+	planner, err := retention.NewPlanner(policy, opts)
 	if err != nil {
 		return nil, err
 	}
-	if checkStatus == nil {
-		checkStatus = func(i int) bool {
-			return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
+
+	comps, err := t.ListCompositions(&composition.GetParams{})
+	for comps != nil && err == nil {
+		if err = planner.AddCompositions(ctx, comps.Compositions); err == nil {
+			comps, err = t.NextCompositionsPage(comps)
 		}
 	}
-
-	if !checkStatus(resp.StatusCode) {
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf(
-			"unexpected status code, status: %v, message: %s",
-			resp.StatusCode,
-			msg,
-		)
+	if err != nil {
+		return nil, err
 	}
-	return io.ReadAll(resp.Body)
-}
 
-func (t *Twilio) validateResolution(param video.VideoLayouter) error {
-	resolution := param.GetVideoLayout().GetResolution()
-	if param.GetResolution() == nil {
-		resolution = composition.VGA
-		if resolution == param.GetVideoLayout().GetResolution() {
-			return nil
-		}
-	} else {
-		if *param.GetResolution() == resolution {
-			return nil
+	recs, err := t.ListRecordings(RecordingFilter{})
+	for recs != nil && err == nil {
+		if err = planner.AddRecordings(ctx, recs.Recordings); err == nil {
+			recs, err = t.NextRecordingsPage(recs)
 		}
 	}
+	if err != nil {
+		return nil, err
+	}
+	return planner.Plan(), nil
+}
 
-	return errors.New("Error, miss match resolution that given video layout.")
+// ExecuteRetention deletes the media of the plan, see retention.Execute.
+func (t *Twilio) ExecuteRetention(
+	ctx context.Context,
+	plan *retention.Plan,
+	opts retention.ExecuteOptions,
+) (*retention.Result, error) {
+	return retention.Execute(ctx, t, plan, opts)
 }
 
-func (t *Twilio) GetRoomInstance(roomSid string) (*rooms.RoomInstance, error) {
-	dst := &rooms.RoomInstance{}
+// RecordingFilter narrows ListRecordings, its zero fields are not filtered on.
+type RecordingFilter struct {
+	MediaType      recording.Type
+	RoomSid        string
+	ParticipantSid string
+	Status         recording.Status
+	SourceSid      string
+
+	// Read only the recordings created on or after this time.
+	DateCreatedAfter time.Time
+
+	// Read only the recordings created before this time.
+	DateCreatedBefore time.Time
+
+	// How many resources to return in each list page. The default is 50, and the maximum is 1000.
+	PageSize uint
+}
+
+func (f RecordingFilter) values() url.Values {
+	params := url.Values{}
+	// A participant only belongs to one room, so its SID alone narrows the recordings
+	// to both and GroupingSid is given once.
+	if f.ParticipantSid != "" {
+		params.Set("GroupingSid", f.ParticipantSid)
+	} else if f.RoomSid != "" {
+		params.Set("GroupingSid", f.RoomSid)
+	}
+	if f.MediaType != "" {
+		params.Set("MediaType", string(f.MediaType))
+	}
+	if f.Status != "" {
+		params.Set("Status", string(f.Status))
+	}
+	if f.SourceSid != "" {
+		params.Set("SourceSid", f.SourceSid)
+	}
+	if !f.DateCreatedAfter.IsZero() {
+		params.Set("DateCreatedAfter", f.DateCreatedAfter.UTC().Format(time.RFC3339))
+	}
+	if !f.DateCreatedBefore.IsZero() {
+		params.Set("DateCreatedBefore", f.DateCreatedBefore.UTC().Format(time.RFC3339))
+	}
+	if f.PageSize != 0 {
+		params.Set("PageSize", strconv.FormatUint(uint64(f.PageSize), 10))
+	}
+	return params
+}
+
+func (t *Twilio) ListRecordings(
+	filter RecordingFilter,
+) (*recording.RecordingList, error) {
+	dst := &recording.RecordingList{}
 	if err := t.request(
 		http.MethodGet,
-		t.baseUrl.WithRoomsURI()+"/"+roomSid,
+		t.baseUrl.WithRecordingsURIAndQueryParam(filter.values()),
 		"",
 		nil,
 		nil,
@@ -321,18 +465,35 @@ func (t *Twilio) GetRoomInstance(roomSid string) (*rooms.RoomInstance, error) {
 	return dst, nil
 }
 
-func (t *Twilio) ListCompletedRooms(size uint) (*rooms.RoomInstanceList, error) {
-	return t.ListRooms(url.Values{
-		"Status":   []string{"completed"},
-		"PageSize": []string{fmt.Sprintf("%d", size)},
-	})
+// RoomRecordingFilter narrows ListRoomRecordings, its zero fields are not filtered on.
+type RoomRecordingFilter struct {
+	Status            recording.Status
+	SourceSid         string
+	DateCreatedAfter  time.Time
+	DateCreatedBefore time.Time
+	PageSize          uint
 }
 
-func (t *Twilio) ListRooms(params url.Values) (*rooms.RoomInstanceList, error) {
-	dst := &rooms.RoomInstanceList{}
+// ListRoomRecordings lists the recordings of the room through the room's own endpoint.
+func (t *Twilio) ListRoomRecordings(
+	roomSid string,
+	filter RoomRecordingFilter,
+) (*recording.RecordingList, error) {
+	if roomSid == "" {
+		return nil, errors.New("Room SID must not be empty")
+	}
+	params := RecordingFilter{
+		Status:            filter.Status,
+		SourceSid:         filter.SourceSid,
+		DateCreatedAfter:  filter.DateCreatedAfter,
+		DateCreatedBefore: filter.DateCreatedBefore,
+		PageSize:          filter.PageSize,
+	}.values()
+
+	dst := &recording.RecordingList{}
 	if err := t.request(
 		http.MethodGet,
-		t.baseUrl.WithRoomsURIAndQueryParameters(params),
+		t.baseUrl.WithRoomRecordingsURI(roomSid)+"?"+params.Encode(),
 		"",
 		nil,
 		nil,
@@ -343,109 +504,612 @@ func (t *Twilio) ListRooms(params url.Values) (*rooms.RoomInstanceList, error) {
 	return dst, nil
 }
 
-func (t *Twilio) request(
-	method, url, contentType string,
-	body io.Reader,
-	checkStatus func(int) bool,
-	dst interface{},
-) error {
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return err
+// NextRecordingsPage fetches the page after the given one.
+// It returns nil without error when the given page is the last one.
+func (t *Twilio) NextRecordingsPage(page *recording.RecordingList) (*recording.RecordingList, error) {
+	dst := &recording.RecordingList{}
+	if ok, err := t.nextPage(page.Meta.NextPageUrl, dst); !ok {
+		return nil, err
 	}
+	return dst, nil
+}
 
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
+// ListAllRecordings lists the recordings matching the filter, following every page.
+func (t *Twilio) ListAllRecordings(filter RecordingFilter) ([]recording.RecordingInstance, error) {
+	var ret []recording.RecordingInstance
+	page, err := t.ListRecordings(filter)
+	for page != nil && err == nil {
+		ret = append(ret, page.Recordings...)
+		page, err = t.NextRecordingsPage(page)
 	}
-	respBody, err := t.fireWithAuth(req, checkStatus)
 	if err != nil {
-		return err
-	}
-
-	if dst == nil {
-		return nil
+		return nil, err
 	}
-	return json.Unmarshal(respBody, dst)
+	return ret, nil
 }
 
-func (t *Twilio) formValues(p video.VideoLayouter) (url.Values, error) {
-	var regionBytes []byte
-	layout := p.GetVideoLayout()
-	hasVideolayout := layout != nil
-	if hasVideolayout {
-		regionMap := make(map[string]interface{})
-		for _, r := range layout.GetRegions() {
-			if r == nil {
-				return nil, errors.New("Error, the region is nil.")
-			}
-			if r.Prop == nil {
-				return nil, errors.New("Error, the region must have properties.")
-			}
-
-			propBytes, err := json.Marshal(r.Prop)
-			if err != nil {
-				return nil, err
-			}
-
-			propObj := make(map[string]interface{})
-			if err := json.Unmarshal(propBytes, &propObj); err != nil {
-				return nil, err
-			}
+func (t *Twilio) GetRecording(recordingSid string) (*recording.RecordingInstance, error) {
+	if recordingSid == "" {
+		return nil, errors.New("Recording SID must not be empty")
+	}
 
-			regionMap[r.Name] = propObj
-		}
+	dst := &recording.RecordingInstance{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithRecordingsURIAndPathParam(recordingSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
 
-		var err error
-		regionBytes, err = json.Marshal(regionMap)
-		if err != nil {
-			return nil, err
-		}
+// DeleteRecording deletes the recording's media. Its metadata stays with the deleted status.
+func (t *Twilio) DeleteRecording(recordingSid string) error {
+	if recordingSid == "" {
+		return errors.New("Recording SID must not be empty")
 	}
+	return t.request(
+		http.MethodDelete,
+		t.baseUrl.WithRecordingsURIAndPathParam(recordingSid),
+		"",
+		nil,
+		nil,
+		nil,
+	)
+}
 
-	url, err := form.EncodeToValues(p)
+// AlignRoomRecordings places the room's recordings on the room's timeline for offline mixing.
+func (t *Twilio) AlignRoomRecordings(roomSid string) (*timeline.Alignment, error) {
+	room, err := t.GetRoomInstance(roomSid)
 	if err != nil {
 		return nil, err
 	}
-
-	if hasVideolayout {
-		url.Set("VideoLayout", string(regionBytes))
+	recs, err := t.ListAllRecordings(RecordingFilter{RoomSid: roomSid})
+	if err != nil {
+		return nil, err
 	}
-	return url, nil
+	return timeline.Align(roomSid, room.DateCreated, recs), nil
 }
 
-func (t *Twilio) CreateRoom(param *rooms.RoomPostParams) (*rooms.RoomInstance, error) {
-	body, err := form.EncodeToValues(param)
+// MixCommand returns the ffmpeg command mixing the room's recordings as the params describe,
+// an alternative to CreateComposition. The command is not run.
+func (t *Twilio) MixCommand(param *composition.ComposeParams, opts ffmpeg.Options) (*ffmpeg.Command, error) {
+	if err := t.validateResolution(param); err != nil {
+		return nil, err
+	}
+	room, err := t.GetRoomInstance(param.RoomSid)
 	if err != nil {
 		return nil, err
 	}
-
-	resp := &rooms.RoomInstance{}
-	if err := t.request(
-		http.MethodPost,
-		t.baseUrl.WithRoomsURI(),
-		"application/x-www-form-urlencoded",
-		strings.NewReader(body.Encode()),
-		nil,
-		resp,
-	); err != nil {
+	recs, err := t.ListAllRecordings(RecordingFilter{RoomSid: param.RoomSid})
+	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	return ffmpeg.Generate(recs, room.DateCreated, param, opts)
 }
 
-var client = &http.Client{
-	CheckRedirect: func(req *http.Request, via []*http.Request) error {
-		return http.ErrUseLastResponse
-	},
+// SimulateRoomPlacement predicts where the room's video tracks would be placed
+// by the layout, matching its sources against the room's recorded track names.
+func (t *Twilio) SimulateRoomPlacement(
+	layout *video.VideoLayout,
+	roomSid string,
+) ([]placement.Slice, error) {
+	room, err := t.GetRoomInstance(roomSid)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := t.ListAllRecordings(RecordingFilter{
+		MediaType: MediaTypeVideo,
+		RoomSid:   roomSid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return placement.Simulate(layout, placement.TracksFromRecordings(recs, room.DateCreated))
 }
 
-type AuthenticateMediaLinkOptions struct {
-	Ttl int // Default to 3600
-}
+// PreviewAudioSources returns the room's audio recordings
+// that a composition with the params' audio sources would mix.
+func (t *Twilio) PreviewAudioSources(
+	roomSid string,
+	param composition.AudioSourcer,
+) ([]recording.RecordingInstance, error) {
+	recs, err := t.ListAllRecordings(RecordingFilter{
+		MediaType: MediaTypeAudio,
+		RoomSid:   roomSid,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func (t *Twilio) AuthenticateMediaLink(
-	ctx context.Context,
-	rawUrl string,
+	names := make([]string, len(recs))
+	for i, rec := range recs {
+		names[i] = rec.TrackName
+	}
+	included := map[string]bool{}
+	for _, name := range composition.IncludedAudioTracks(param, names) {
+		included[name] = true
+	}
+
+	var ret []recording.RecordingInstance
+	for _, rec := range recs {
+		if included[rec.TrackName] {
+			ret = append(ret, rec)
+		}
+	}
+	return ret, nil
+}
+
+// GetRecordingMedia returns the link to the recording's media stored by Twilio.
+//
+// Deprecated: the link is wrong for media stored externally, which Twilio does not serve.
+// Use OpenRecordingMedia, which reads the media wherever it is.
+func (t *Twilio) GetRecordingMedia(recordingSid string) (*recording.Media, error) {
+	dst := &recording.Media{}
+	return dst, t.request(
+		http.MethodGet,
+		t.baseUrl.WithRecordingsURIAndPathParam(recordingSid)+"/Media",
+		"",
+		nil,
+		func(status int) bool { return status == http.StatusFound },
+		dst,
+	)
+}
+
+// GetCompositionMedia requests the composition's media stored by Twilio.
+//
+// Deprecated: it fails for media stored externally, which Twilio does not serve.
+// Use OpenCompositionMedia, which reads the media wherever it is.
+func (t *Twilio) GetCompositionMedia(comSid string) (*composition.Composition, error) {
+	ret := &composition.Composition{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithCompositionURIMedia(comSid),
+		"",
+		nil,
+		nil,
+		ret,
+	); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// SetMediaFetcher sets the fetcher of media stored externally, the media whose
+// media_external_location is set. Without one, opening such media fails with *media.NoFetcherError.
+func (t *Twilio) SetMediaFetcher(f media.Fetcher) {
+	t.fetcher = f
+}
+
+// OpenRecordingMedia streams the recording's media, wherever it is stored. The caller closes the reader.
+func (t *Twilio) OpenRecordingMedia(ctx context.Context, recordingSid string) (*media.Reader, error) {
+	rec, err := t.GetRecording(recordingSid)
+	if err != nil {
+		return nil, err
+	}
+	return t.OpenRecording(ctx, rec)
+}
+
+// OpenRecording streams the media of the recording, from its external location
+// through the media fetcher when it has one, from Twilio otherwise. The caller closes the reader.
+func (t *Twilio) OpenRecording(ctx context.Context, rec *recording.RecordingInstance) (*media.Reader, error) {
+	if rec.MediaExternalLocation != "" {
+		return t.openExternal(ctx, rec.MediaExternalLocation)
+	}
+	return t.openMedia(ctx, t.baseUrl.WithRecordingsURIAndPathParam(rec.Sid)+"/Media")
+}
+
+// OpenCompositionMedia streams the composition's media, wherever it is stored. The caller closes the reader.
+func (t *Twilio) OpenCompositionMedia(ctx context.Context, compositionSid string) (*media.Reader, error) {
+	c, err := t.GetComposition(compositionSid)
+	if err != nil {
+		return nil, err
+	}
+	return t.OpenComposition(ctx, c)
+}
+
+// OpenComposition streams the media of the composition, from its external location
+// through the media fetcher when it has one, from Twilio otherwise. The caller closes the reader.
+func (t *Twilio) OpenComposition(ctx context.Context, c *composition.Composition) (*media.Reader, error) {
+	if c.MediaExternalLocation != "" {
+		return t.openExternal(ctx, c.MediaExternalLocation)
+	}
+	return t.openMedia(ctx, t.baseUrl.WithCompositionURIMedia(c.Sid))
+}
+
+func (t *Twilio) openExternal(ctx context.Context, location string) (*media.Reader, error) {
+	if t.fetcher == nil {
+		return nil, &media.NoFetcherError{Location: location}
+	}
+	return t.fetcher.Fetch(ctx, location)
+}
+
+// openMedia resolves the media link and downloads from the signed URL it redirects to,
+// which must not be sent the account's credentials.
+func (t *Twilio) openMedia(ctx context.Context, mediaUrl string) (*media.Reader, error) {
+	link, err := t.AuthenticateMediaLink(ctx, mediaUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return media.FromResponse(resp)
+}
+
+// PollArchive archives the completed compositions and recordings created since the given time.
+// Media already archived is skipped, so polls may overlap.
+func (t *Twilio) PollArchive(ctx context.Context, a *archive.Archiver, since time.Time) (*archive.Report, error) {
+	status := composition.StatusCompleted
+	after := since.UTC().Format(time.RFC3339)
+	var comps []composition.Composition
+	page, err := t.ListCompositions(&composition.GetParams{Status: &status, DateCreatedAfter: &after})
+	for page != nil && err == nil {
+		comps = append(comps, page.Compositions...)
+		page, err = t.NextCompositionsPage(page)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	recs, err := t.ListAllRecordings(RecordingFilter{
+		Status:           recording.StatusCompleted,
+		DateCreatedAfter: since,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.Archive(ctx, comps, recs)
+}
+
+func (t *Twilio) fireWithAuth(req *http.Request, checkStatus func(int) bool) ([]byte, error) {
+	req.SetBasicAuth(t.cred.ApiKeySid, t.cred.ApiKeySecret)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if checkStatus == nil {
+		checkStatus = func(i int) bool {
+			return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
+		}
+	}
+
+	if !checkStatus(resp.StatusCode) {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Message: string(msg)}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// StatusError is returned when Twilio answers with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code, status: %v, message: %s", e.StatusCode, e.Message)
+}
+
+func (t *Twilio) validateResolution(param video.VideoLayouter) error {
+	if param.GetVideoLayout() == nil {
+		return nil
+	}
+
+	resolution := video.DefaultResolution()
+	if param.GetResolution() != nil {
+		resolution = *param.GetResolution()
+	}
+	if resolution == param.GetVideoLayout().GetResolution() {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"Error, resolution %v does not match the video layout's resolution %v.",
+		resolution,
+		param.GetVideoLayout().GetResolution(),
+	)
+}
+
+func (t *Twilio) GetRoomInstance(roomSid string) (*rooms.RoomInstance, error) {
+	dst := &rooms.RoomInstance{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithRoomsURIAndPathParam(roomSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// GetRoomByUniqueName fetches an in-progress room by its unique name.
+// Twilio only resolves unique names of in-progress rooms,
+// use ListRooms with RoomGetParams.UniqueName for the ended ones.
+func (t *Twilio) GetRoomByUniqueName(uniqueName string) (*rooms.RoomInstance, error) {
+	if uniqueName == "" {
+		return nil, errors.New("Unique name must not be empty")
+	}
+	return t.GetRoomInstance(uniqueName)
+}
+
+func (t *Twilio) ListCompletedRooms(size uint) (*rooms.RoomInstanceList, error) {
+	status := rooms.RoomStatusCompleted
+	return t.ListRooms(&rooms.RoomGetParams{
+		Status:   &status,
+		PageSize: &size,
+	})
+}
+
+func (t *Twilio) ListRooms(param *rooms.RoomGetParams) (*rooms.RoomInstanceList, error) {
+	if param == nil {
+		param = &rooms.RoomGetParams{}
+	}
+	values, err := form.EncodeToValues(param)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := &rooms.RoomInstanceList{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithRoomsURIAndQueryParameters(values),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// NextRoomsPage fetches the page after the given one.
+// It returns nil without error when the given page is the last one.
+func (t *Twilio) NextRoomsPage(page *rooms.RoomInstanceList) (*rooms.RoomInstanceList, error) {
+	dst := &rooms.RoomInstanceList{}
+	if ok, err := t.nextPage(&page.Meta.NextPageURL, dst); !ok {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func (t *Twilio) UpdateRoom(roomSid string, param *rooms.RoomUpdateParams) (*rooms.RoomInstance, error) {
+	if roomSid == "" {
+		return nil, errors.New("Room SID must not be empty")
+	}
+	body, err := form.EncodeToValues(param)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := &rooms.RoomInstance{}
+	if err := t.request(
+		http.MethodPost,
+		t.baseUrl.WithRoomsURIAndPathParam(roomSid),
+		"application/x-www-form-urlencoded",
+		strings.NewReader(body.Encode()),
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// CompleteRoom ends the room, disconnecting all its participants.
+// Compositions hooks are triggered once the room is completed.
+func (t *Twilio) CompleteRoom(roomSid string) (*rooms.RoomInstance, error) {
+	return t.UpdateRoom(roomSid, &rooms.RoomUpdateParams{Status: rooms.RoomStatusCompleted})
+}
+
+func (t *Twilio) GetRecordingRules(roomSid string) (*rooms.RoomRecordingRules, error) {
+	if roomSid == "" {
+		return nil, errors.New("Room SID must not be empty")
+	}
+
+	dst := &rooms.RoomRecordingRules{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithRecordingRulesURI(roomSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// UpdateRecordingRules replaces the recording rules of an in-progress room.
+// The rules are validated before being sent.
+func (t *Twilio) UpdateRecordingRules(roomSid string, rules []rooms.RecordingRule) (*rooms.RoomRecordingRules, error) {
+	if roomSid == "" {
+		return nil, errors.New("Room SID must not be empty")
+	}
+	if err := rooms.ValidateRecordingRules(rules); err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []rooms.RecordingRule{}
+	}
+	rulesBytes, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	body := url.Values{"Rules": {string(rulesBytes)}}
+
+	dst := &rooms.RoomRecordingRules{}
+	if err := t.request(
+		http.MethodPost,
+		t.baseUrl.WithRecordingRulesURI(roomSid),
+		"application/x-www-form-urlencoded",
+		strings.NewReader(body.Encode()),
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// PreviewRecordedTracks evaluates the room's recording rules against the tracks
+// currently published by its connected participants.
+func (t *Twilio) PreviewRecordedTracks(roomSid string) ([]participants.RoomTrack, error) {
+	rules, err := t.GetRecordingRules(roomSid)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := t.roomTracks(roomSid)
+	if err != nil {
+		return nil, err
+	}
+	var recorded []participants.RoomTrack
+	for _, track := range tracks {
+		if rooms.IsRecorded(rules.Rules, track.RuleTrack()) {
+			recorded = append(recorded, track)
+		}
+	}
+	return recorded, nil
+}
+
+// WaitRoomEnded polls the room every interval until it is completed or failed,
+// or until ctx is done. The interval must be positive.
+func (t *Twilio) WaitRoomEnded(
+	ctx context.Context,
+	roomSid string,
+	interval time.Duration,
+) (*rooms.RoomInstance, error) {
+	return rooms.WaitEnded(ctx, func() (*rooms.RoomInstance, error) {
+		return t.GetRoomInstance(roomSid)
+	}, interval)
+}
+
+func (t *Twilio) request(
+	method, url, contentType string,
+	body io.Reader,
+	checkStatus func(int) bool,
+	dst interface{},
+) error {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	respBody, err := t.fireWithAuth(req, checkStatus)
+	if err != nil {
+		return err
+	}
+
+	if dst == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, dst)
+}
+
+// nextPage fetches the page at nextPageUrl into dst.
+// It reports false when there is no next page.
+func (t *Twilio) nextPage(nextPageUrl *string, dst interface{}) (bool, error) {
+	if nextPageUrl == nil || *nextPageUrl == "" {
+		return false, nil
+	}
+	if err := t.request(
+		http.MethodGet,
+		*nextPageUrl,
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *Twilio) formValues(p video.VideoLayouter) (url.Values, error) {
+	var layoutBytes []byte
+	layout := p.GetVideoLayout()
+	hasVideolayout := layout != nil
+	if hasVideolayout {
+		if err := layout.Validate(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		layoutBytes, err = json.Marshal(layout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	url, err := form.EncodeToValues(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasVideolayout {
+		url.Set("VideoLayout", string(layoutBytes))
+	}
+	if as, ok := p.(composition.AudioSourcer); ok {
+		composition.AddAudioSources(url, as)
+	}
+	return url, nil
+}
+
+func (t *Twilio) CreateRoom(param *rooms.RoomPostParams) (*rooms.RoomInstance, error) {
+	if param.RecordingRules != nil {
+		if err := rooms.ValidateRecordingRules(param.RecordingRules.Rules); err != nil {
+			return nil, err
+		}
+	}
+	body, err := form.EncodeToValues(param)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &rooms.RoomInstance{}
+	if err := t.request(
+		http.MethodPost,
+		t.baseUrl.WithRoomsURI(),
+		"application/x-www-form-urlencoded",
+		strings.NewReader(body.Encode()),
+		nil,
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+var client = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+type AuthenticateMediaLinkOptions struct {
+	Ttl int // Default to 3600
+}
+
+func (t *Twilio) AuthenticateMediaLink(
+	ctx context.Context,
+	rawUrl string,
 	opts *AuthenticateMediaLinkOptions,
 ) (string, error) {
 	defaultOpts := &AuthenticateMediaLinkOptions{
@@ -493,16 +1157,337 @@ func (t *Twilio) AuthenticateMediaLink(
 	return responseBody.RedirecTo, nil
 }
 
+// GetParticipantsByRoomSid lists all the room's participants, following every page.
 func (t *Twilio) GetParticipantsByRoomSid(roomSid string) ([]participants.ParticipantInstance, error) {
-	resp := new(struct {
-		Participants []participants.ParticipantInstance `json:"participants"`
-	})
-	return resp.Participants, t.request(
+	page, err := t.ListParticipants(roomSid, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []participants.ParticipantInstance
+	for page != nil {
+		ret = append(ret, page.Participants...)
+		if page, err = t.NextParticipantsPage(page); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func (t *Twilio) GetParticipant(roomSid, participantSid string) (*participants.ParticipantInstance, error) {
+	if roomSid == "" || participantSid == "" {
+		return nil, errors.New("Room SID and participant SID must not be empty")
+	}
+
+	dst := &participants.ParticipantInstance{}
+	if err := t.request(
 		http.MethodGet,
-		t.baseUrl.WithRoomParticipantsURI(roomSid),
+		t.baseUrl.WithRoomParticipantsURIAndPathParam(roomSid, participantSid),
 		"",
 		nil,
 		nil,
-		resp,
-	)
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// GetParticipantByIdentity fetches the connected participant with the identity.
+// Twilio only resolves identities of connected participants,
+// use ListParticipants with ParticipantGetParams.Identity for the disconnected ones.
+func (t *Twilio) GetParticipantByIdentity(roomSid, identity string) (*participants.ParticipantInstance, error) {
+	if identity == "" {
+		return nil, errors.New("Identity must not be empty")
+	}
+	return t.GetParticipant(roomSid, identity)
+}
+
+func (t *Twilio) ListParticipants(
+	roomSid string,
+	param *participants.ParticipantGetParams,
+) (*participants.ParticipantList, error) {
+	if roomSid == "" {
+		return nil, errors.New("Room SID must not be empty")
+	}
+	if param == nil {
+		param = &participants.ParticipantGetParams{}
+	}
+	values, err := form.EncodeToValues(param)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := &participants.ParticipantList{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithRoomParticipantsURIAndQueryParameters(roomSid, values),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// NextParticipantsPage fetches the page after the given one.
+// It returns nil without error when the given page is the last one.
+func (t *Twilio) NextParticipantsPage(page *participants.ParticipantList) (*participants.ParticipantList, error) {
+	dst := &participants.ParticipantList{}
+	if ok, err := t.nextPage(&page.Meta.NextPageURL, dst); !ok {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func (t *Twilio) ListPublishedTracks(roomSid, participantSid string) (*participants.PublishedTrackList, error) {
+	if roomSid == "" || participantSid == "" {
+		return nil, errors.New("Room SID and participant SID must not be empty")
+	}
+
+	dst := &participants.PublishedTrackList{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithPublishedTracksURI(roomSid, participantSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// NextPublishedTracksPage fetches the page after the given one.
+// It returns nil without error when the given page is the last one.
+func (t *Twilio) NextPublishedTracksPage(page *participants.PublishedTrackList) (*participants.PublishedTrackList, error) {
+	dst := &participants.PublishedTrackList{}
+	if ok, err := t.nextPage(&page.Meta.NextPageURL, dst); !ok {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// GetPublishedTrack fetches a published track by its SID or name.
+func (t *Twilio) GetPublishedTrack(roomSid, participantSid, trackSid string) (*participants.PublishedTrack, error) {
+	if roomSid == "" || participantSid == "" || trackSid == "" {
+		return nil, errors.New("Room SID, participant SID and track SID must not be empty")
+	}
+
+	dst := &participants.PublishedTrack{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithPublishedTracksURIAndPathParam(roomSid, participantSid, trackSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func (t *Twilio) ListSubscribedTracks(roomSid, participantSid string) (*participants.SubscribedTrackList, error) {
+	if roomSid == "" || participantSid == "" {
+		return nil, errors.New("Room SID and participant SID must not be empty")
+	}
+
+	dst := &participants.SubscribedTrackList{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithSubscribedTracksURI(roomSid, participantSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// NextSubscribedTracksPage fetches the page after the given one.
+// It returns nil without error when the given page is the last one.
+func (t *Twilio) NextSubscribedTracksPage(page *participants.SubscribedTrackList) (*participants.SubscribedTrackList, error) {
+	dst := &participants.SubscribedTrackList{}
+	if ok, err := t.nextPage(&page.Meta.NextPageURL, dst); !ok {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func (t *Twilio) GetSubscribedTrack(roomSid, participantSid, trackSid string) (*participants.SubscribedTrack, error) {
+	if roomSid == "" || participantSid == "" || trackSid == "" {
+		return nil, errors.New("Room SID, participant SID and track SID must not be empty")
+	}
+
+	dst := &participants.SubscribedTrack{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithSubscribedTracksURIAndPathParam(roomSid, participantSid, trackSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func (t *Twilio) GetSubscribeRules(roomSid, participantSid string) (*participants.SubscribeRules, error) {
+	if roomSid == "" || participantSid == "" {
+		return nil, errors.New("Room SID and participant SID must not be empty")
+	}
+
+	dst := &participants.SubscribeRules{}
+	if err := t.request(
+		http.MethodGet,
+		t.baseUrl.WithSubscribeRulesURI(roomSid, participantSid),
+		"",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// UpdateSubscribeRules replaces the participant's subscribe rules.
+// The rules are validated before being sent.
+func (t *Twilio) UpdateSubscribeRules(
+	roomSid string,
+	participantSid string,
+	rules []participants.SubscribeRule,
+) (*participants.SubscribeRules, error) {
+	if roomSid == "" || participantSid == "" {
+		return nil, errors.New("Room SID and participant SID must not be empty")
+	}
+	if err := participants.ValidateSubscribeRules(rules); err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []participants.SubscribeRule{}
+	}
+	rulesBytes, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	body := url.Values{"Rules": {string(rulesBytes)}}
+
+	dst := &participants.SubscribeRules{}
+	if err := t.request(
+		http.MethodPost,
+		t.baseUrl.WithSubscribeRulesURI(roomSid, participantSid),
+		"application/x-www-form-urlencoded",
+		strings.NewReader(body.Encode()),
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// PredictSubscribedTracks evaluates the participant's subscribe rules against the tracks
+// currently published by the room's connected participants.
+// The participant is given by its SID or identity.
+func (t *Twilio) PredictSubscribedTracks(roomSid, participantSid string) ([]participants.RoomTrack, error) {
+	rules, err := t.GetSubscribeRules(roomSid, participantSid)
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := t.GetParticipant(roomSid, participantSid)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := t.roomTracks(roomSid)
+	if err != nil {
+		return nil, err
+	}
+	return participants.SubscribedTracksOf(subscriber.Sid, rules.Rules, tracks), nil
+}
+
+// roomTracks lists the tracks published by the room's connected participants.
+func (t *Twilio) roomTracks(roomSid string) ([]participants.RoomTrack, error) {
+	publishers, err := t.GetParticipantsByRoomSid(roomSid)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []participants.RoomTrack
+	for _, p := range publishers {
+		page, err := t.ListPublishedTracks(roomSid, p.Sid)
+		for page != nil && err == nil {
+			for _, track := range page.PublishedTracks {
+				tracks = append(tracks, participants.RoomTrack{
+					PublishedTrack:    track,
+					PublisherIdentity: p.Identity,
+				})
+			}
+			page, err = t.NextPublishedTracksPage(page)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tracks, nil
+}
+
+// AnonymizeParticipant replaces the participant's identity with its SID.
+// Only disconnected participants can be anonymized.
+func (t *Twilio) AnonymizeParticipant(roomSid, participantSid string) (*participants.ParticipantInstance, error) {
+	if roomSid == "" || participantSid == "" {
+		return nil, errors.New("Room SID and participant SID must not be empty")
+	}
+
+	dst := &participants.ParticipantInstance{}
+	if err := t.request(
+		http.MethodPost,
+		t.baseUrl.WithAnonymizeURI(roomSid, participantSid),
+		"application/x-www-form-urlencoded",
+		nil,
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// AnonymizeIdentity anonymizes the participants with the identity across the completed rooms.
+// See anonymize.Identity.
+func (t *Twilio) AnonymizeIdentity(ctx context.Context, opts anonymize.Options) (*anonymize.Report, error) {
+	return anonymize.Identity(ctx, t, opts)
+}
+
+// DisconnectParticipant removes the participant from the room.
+func (t *Twilio) DisconnectParticipant(roomSid, participantSid string) (*participants.ParticipantInstance, error) {
+	if roomSid == "" || participantSid == "" {
+		return nil, errors.New("Room SID and participant SID must not be empty")
+	}
+	body, err := form.EncodeToValues(&participants.ParticipantUpdateParams{
+		Status: participants.StatusDisconnected,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dst := &participants.ParticipantInstance{}
+	if err := t.request(
+		http.MethodPost,
+		t.baseUrl.WithRoomParticipantsURIAndPathParam(roomSid, participantSid),
+		"application/x-www-form-urlencoded",
+		strings.NewReader(body.Encode()),
+		nil,
+		dst,
+	); err != nil {
+		return nil, err
+	}
+	return dst, nil
 }
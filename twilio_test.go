@@ -5,12 +5,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/anonymize"
+	"github.com/matthxwpavin/twilio-compositions/video/archive"
 	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/ffmpeg"
+	"github.com/matthxwpavin/twilio-compositions/video/media"
+	"github.com/matthxwpavin/twilio-compositions/video/participants"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+	"github.com/matthxwpavin/twilio-compositions/video/retention"
 	"github.com/matthxwpavin/twilio-compositions/video/rooms"
 	"github.com/pelletier/go-toml"
 )
@@ -72,8 +81,41 @@ func TestListEnabledCompositionHooks(t *testing.T) {
 	fmt.Println(dst.String())
 }
 
+func TestListCompositionHooks(t *testing.T) {
+	enabled := false
+	hooks, err := twi.ListCompositionHooks(&composition.HooksGetParams{
+		Enabled: &enabled,
+	})
+	if err != nil {
+		t.Fatalf("error to list composition hooks: %v", err)
+	}
+	jsonPrint(hooks)
+
+	next, err := twi.NextCompositionHooksPage(hooks)
+	if err != nil {
+		t.Errorf("error to list next composition hooks page: %v", err)
+	}
+	jsonPrint(next)
+}
+
+func TestGetCompositionHooks(t *testing.T) {
+	hooks, err := twi.GetCompositionHooks("HK9ef12a9c3d22c3c3b05b5f1420125dfc")
+	if err != nil {
+		t.Errorf("error to get composition hooks: %v", err)
+	}
+	jsonPrint(hooks)
+}
+
+func TestDisableCompositionHooks(t *testing.T) {
+	hooks, err := twi.DisableCompositionHooks("HK9ef12a9c3d22c3c3b05b5f1420125dfc")
+	if err != nil {
+		t.Errorf("error to disable composition hooks: %v", err)
+	}
+	jsonPrint(hooks)
+}
+
 func TestCreateComposition(t *testing.T) {
-	v, err := video.NewVideoLayout(composition.VGA)
+	v, err := video.NewVideoLayout(composition.VGA())
 	if err != nil {
 		t.Errorf("error to new video composition: %v", err)
 	}
@@ -95,12 +137,12 @@ func TestCreateComposition(t *testing.T) {
 	var (
 		trim      = true
 		AudSource = "*"
-		res       = composition.VGA
+		res       = composition.VGA()
 	)
 	comp, err := twi.CreateComposition(&composition.ComposeParams{
 		RoomSid:              "RM25d7091d712e6f2ef1a589be78976596",
 		VideoLayout:          v,
-		AudioSources:         &AudSource,
+		AudioSources:         []string{AudSource},
 		AudioSourcesExcluded: nil,
 		Resolution:           &res,
 		Format:               composition.MP4,
@@ -119,7 +161,7 @@ func TestDeleteCompositionHooks(t *testing.T) {
 }
 
 func TestCreateCompositionHooks(t *testing.T) {
-	v, err := video.NewVideoLayout(composition.VGA)
+	v, err := video.NewVideoLayout(composition.VGA())
 	if err != nil {
 		t.Errorf("error to new video composition: %v", err)
 	}
@@ -141,14 +183,14 @@ func TestCreateCompositionHooks(t *testing.T) {
 	var (
 		trim      = true
 		AudSource = "*"
-		res       = composition.VGA
+		res       = composition.VGA()
 		enabled   = true
 	)
 	_, err = twi.CreateCompositionHooks(&composition.HooksParams{
 		FriendlyName:         "ClicknicCompositionHooks",
 		Enabled:              &enabled,
 		VideoLayout:          v,
-		AudioSources:         &AudSource,
+		AudioSources:         []string{AudSource},
 		AudioSourcesExcluded: nil,
 		Resolution:           &res,
 		Format:               composition.MP4,
@@ -160,7 +202,7 @@ func TestCreateCompositionHooks(t *testing.T) {
 }
 
 func TestUpdateCompositionHooks(t *testing.T) {
-	v, err := video.NewVideoLayout(composition.VGA)
+	v, err := video.NewVideoLayout(composition.VGA())
 	if err != nil {
 		t.Errorf("error to new video composition: %v", err)
 	}
@@ -182,7 +224,7 @@ func TestUpdateCompositionHooks(t *testing.T) {
 	var (
 		trim      = true
 		AudSource = "*"
-		res       = composition.VGA
+		res       = composition.VGA()
 		enabled   = false
 	)
 	_, err = twi.UpdateCompositionHooks(
@@ -191,7 +233,7 @@ func TestUpdateCompositionHooks(t *testing.T) {
 			FriendlyName:         "ClicknicCompositionHooks",
 			Enabled:              &enabled,
 			VideoLayout:          v,
-			AudioSources:         &AudSource,
+			AudioSources:         []string{AudSource},
 			AudioSourcesExcluded: nil,
 			Resolution:           &res,
 			Format:               composition.MP4,
@@ -270,6 +312,45 @@ func TestGetRoomBySid(t *testing.T) {
 	jsonPrint(room)
 }
 
+func TestGetRoomByUniqueName(t *testing.T) {
+	room, err := twi.GetRoomByUniqueName("TestRoom2")
+	if err != nil {
+		t.Errorf("error to get a room: %v", err)
+	}
+
+	jsonPrint(room)
+}
+
+func TestListRoomsByUniqueName(t *testing.T) {
+	status := rooms.RoomStatusCompleted
+	uniqueName := "TestRoom2"
+	list, err := twi.ListRooms(&rooms.RoomGetParams{
+		Status:     &status,
+		UniqueName: &uniqueName,
+	})
+	if err != nil {
+		t.Errorf("error to list rooms: %v", err)
+	}
+
+	jsonPrint(list)
+}
+
+func TestCompleteRoom(t *testing.T) {
+	room, err := twi.CompleteRoom("TestRoom2")
+	if err != nil {
+		t.Fatalf("error to complete a room: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	room, err = twi.WaitRoomEnded(ctx, room.Sid, 5*time.Second)
+	if err != nil {
+		t.Errorf("error to wait for the room to end: %v", err)
+	}
+
+	jsonPrint(room)
+}
+
 func TestListRecordings(t *testing.T) {
 	recs, err := twi.ListRecordings(
 		RecordingFilter{
@@ -283,6 +364,73 @@ func TestListRecordings(t *testing.T) {
 	jsonPrint(recs)
 }
 
+func TestListParticipantRecordings(t *testing.T) {
+	recs, err := twi.ListAllRecordings(RecordingFilter{
+		RoomSid:          "RM06bc1c5ac394effdb919741e792776b6",
+		ParticipantSid:   "PA1e3b0a0d5c8e4f2a9b7c6d5e4f3a2b1c",
+		Status:           recording.StatusCompleted,
+		DateCreatedAfter: time.Date(2021, 5, 18, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Error("could not get recordings", err)
+	}
+	jsonPrint(recs)
+}
+
+func TestListRoomRecordings(t *testing.T) {
+	recs, err := twi.ListRoomRecordings("RM06bc1c5ac394effdb919741e792776b6", RoomRecordingFilter{
+		Status: recording.StatusCompleted,
+	})
+	if err != nil {
+		t.Error("could not get room recordings", err)
+	}
+	jsonPrint(recs)
+}
+
+func TestGetRecording(t *testing.T) {
+	rec, err := twi.GetRecording("RT7d2b8d3cbab1a9e4d4bd8d4b0bd4a2d1")
+	if err != nil {
+		t.Error("could not get the recording", err)
+	}
+	jsonPrint(rec)
+}
+
+func TestAlignRoomRecordings(t *testing.T) {
+	alignment, err := twi.AlignRoomRecordings("RM06bc1c5ac394effdb919741e792776b6")
+	if err != nil {
+		t.Error("could not align room recordings", err)
+	}
+	jsonPrint(alignment)
+}
+
+func TestMixCommand(t *testing.T) {
+	layout, err := video.NewGridLayout(video.VGA(), 4, video.AllSources, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := twi.MixCommand(&composition.ComposeParams{
+		RoomSid:      "RM06bc1c5ac394effdb919741e792776b6",
+		VideoLayout:  layout,
+		AudioSources: []string{"*"},
+		Format:       composition.MP4,
+	}, ffmpeg.Options{Output: "room.mp4"})
+	if err != nil {
+		t.Fatal("could not generate the mix command", err)
+	}
+	fmt.Println(cmd)
+}
+
+func TestPreviewAudioSources(t *testing.T) {
+	recs, err := twi.PreviewAudioSources("RM06bc1c5ac394effdb919741e792776b6", &composition.ComposeParams{
+		AudioSources:         []string{"*"},
+		AudioSourcesExcluded: []string{"screen*"},
+	})
+	if err != nil {
+		t.Error("could not preview audio sources", err)
+	}
+	jsonPrint(recs)
+}
+
 func TestGetRecordingMedia(t *testing.T) {
 	media, err := twi.GetRecordingMedia("RT99545ec1d5c10b9bed40195372544a9d")
 	if err != nil {
@@ -324,3 +472,173 @@ func TestGetRoomParticipants(t *testing.T) {
 	}
 	jsonPrint(resp)
 }
+
+func TestListParticipants(t *testing.T) {
+	status := participants.StatusDisconnected
+	list, err := twi.ListParticipants("RMfcb5d69c724ce93fdf8f14f80134e853", &participants.ParticipantGetParams{
+		Status: &status,
+	})
+	if err != nil {
+		t.Fatalf("Could not list room participants: %v", err)
+	}
+	jsonPrint(list)
+}
+
+func TestGetParticipantByIdentity(t *testing.T) {
+	p, err := twi.GetParticipantByIdentity("TestRoom2", "alice")
+	if err != nil {
+		t.Fatalf("Could not get the participant: %v", err)
+	}
+	jsonPrint(p)
+}
+
+func TestDisconnectParticipant(t *testing.T) {
+	p, err := twi.DisconnectParticipant("TestRoom2", "alice")
+	if err != nil {
+		t.Fatalf("Could not disconnect the participant: %v", err)
+	}
+	jsonPrint(p)
+}
+
+func TestListPublishedTracks(t *testing.T) {
+	tracks, err := twi.ListPublishedTracks("TestRoom2", "alice")
+	if err != nil {
+		t.Fatalf("Could not list published tracks: %v", err)
+	}
+	for _, track := range tracks.PublishedTracks {
+		if track.Kind == rooms.TrackKindVideo {
+			fmt.Println("video track:", track.Name, track.Enabled)
+		}
+	}
+	jsonPrint(tracks)
+}
+
+func TestListSubscribedTracks(t *testing.T) {
+	tracks, err := twi.ListSubscribedTracks("TestRoom2", "alice")
+	if err != nil {
+		t.Fatalf("Could not list subscribed tracks: %v", err)
+	}
+	jsonPrint(tracks)
+}
+
+func TestUpdateSubscribeRules(t *testing.T) {
+	rules, err := twi.UpdateSubscribeRules("TestRoom2", "student1", []participants.SubscribeRule{
+		{Type: participants.RuleTypeInclude, Kind: rooms.TrackKindAudio},
+		{Type: participants.RuleTypeInclude, Publisher: "teacher", Kind: rooms.TrackKindVideo},
+	})
+	if err != nil {
+		t.Fatalf("Could not update subscribe rules: %v", err)
+	}
+	jsonPrint(rules)
+}
+
+func TestPredictSubscribedTracks(t *testing.T) {
+	tracks, err := twi.PredictSubscribedTracks("TestRoom2", "student1")
+	if err != nil {
+		t.Fatalf("Could not predict subscribed tracks: %v", err)
+	}
+	jsonPrint(tracks)
+}
+
+func TestUpdateRecordingRules(t *testing.T) {
+	rules, err := twi.UpdateRecordingRules("TestRoom2", []rooms.RecordingRule{
+		{Type: rooms.RecordingRuleInclude, Kind: rooms.TrackKindAudio},
+		{Type: rooms.RecordingRuleInclude, Publisher: "teacher"},
+	})
+	if err != nil {
+		t.Fatalf("Could not update recording rules: %v", err)
+	}
+	jsonPrint(rules)
+}
+
+func TestPreviewRecordedTracks(t *testing.T) {
+	tracks, err := twi.PreviewRecordedTracks("TestRoom2")
+	if err != nil {
+		t.Fatalf("Could not preview recorded tracks: %v", err)
+	}
+	jsonPrint(tracks)
+}
+
+func TestAnonymizeIdentityDryRun(t *testing.T) {
+	after := "2021-05-01"
+	report, err := twi.AnonymizeIdentity(context.Background(), anonymize.Options{
+		Identity:         "alice",
+		DateCreatedAfter: &after,
+		DryRun:           true,
+	})
+	if err != nil {
+		t.Fatalf("Could not find the participants to anonymize: %v", err)
+	}
+	jsonPrint(report)
+}
+
+func TestGetComposition(t *testing.T) {
+	c, err := twi.GetComposition("CJ8c6b4e1d1a3f2b0c9e7d5a4f3b2c1d0e")
+	if err != nil {
+		t.Error("could not get the composition", err)
+	}
+	jsonPrint(c)
+}
+
+func TestPollArchive(t *testing.T) {
+	dir, err := os.MkdirTemp("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage, err := archive.NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := archive.NewArchiver(twi, storage, archive.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := twi.PollArchive(context.Background(), a, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Error("could not archive media", err)
+	}
+	jsonPrint(report)
+}
+
+func TestRetentionDryRun(t *testing.T) {
+	policy := &retention.Policy{
+		Rules: []retention.Rule{
+			{Name: "failed", Statuses: []string{string(composition.StatusFailed)}},
+			{Name: "90-days", OlderThan: 90 * 24 * time.Hour},
+		},
+		Holds: []string{"legal-*"},
+	}
+	plan, err := twi.PlanRetention(context.Background(), policy, retention.PlannerOptions{})
+	if err != nil {
+		t.Fatal("could not plan the retention", err)
+	}
+	jsonPrint(plan)
+
+	res, err := twi.ExecuteRetention(context.Background(), plan, retention.ExecuteOptions{
+		DryRun: true,
+		Audit:  os.Stdout,
+	})
+	if err != nil {
+		t.Error("could not execute the plan", err)
+	}
+	jsonPrint(res)
+}
+
+func TestOpenRecordingMedia(t *testing.T) {
+	twi.SetMediaFetcher(&media.HTTPFetcher{})
+	defer twi.SetMediaFetcher(nil)
+
+	r, err := twi.OpenRecordingMedia(context.Background(), "RT99545ec1d5c10b9bed40195372544a9d")
+	if err != nil {
+		t.Fatal("could not open the recording media", err)
+	}
+	defer r.Close()
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		t.Error("could not read the recording media", err)
+	}
+	if r.Size >= 0 && n != r.Size {
+		t.Errorf("read %d bytes, expected %d", n, r.Size)
+	}
+}
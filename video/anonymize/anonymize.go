@@ -0,0 +1,169 @@
+// Package anonymize removes an identity from the participants of past rooms,
+// for privacy requests such as GDPR erasure.
+package anonymize
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/participants"
+	"github.com/matthxwpavin/twilio-compositions/video/rooms"
+)
+
+// Client is the part of the Twilio client the workflow uses.
+type Client interface {
+	ListRooms(param *rooms.RoomGetParams) (*rooms.RoomInstanceList, error)
+	NextRoomsPage(page *rooms.RoomInstanceList) (*rooms.RoomInstanceList, error)
+	ListParticipants(roomSid string, param *participants.ParticipantGetParams) (*participants.ParticipantList, error)
+	NextParticipantsPage(page *participants.ParticipantList) (*participants.ParticipantList, error)
+	AnonymizeParticipant(roomSid, participantSid string) (*participants.ParticipantInstance, error)
+}
+
+type Status string
+
+const (
+	// The participant was anonymized.
+	StatusAnonymized Status = "anonymized"
+	// The participant would be anonymized, it was found on a dry run.
+	StatusPending Status = "pending"
+	// Anonymizing the participant failed, a later run retries it.
+	StatusFailed Status = "failed"
+)
+
+// Entry is the outcome for one participant.
+type Entry struct {
+	RoomSid        string    `json:"room_sid"`
+	ParticipantSid string    `json:"participant_sid"`
+	Status         Status    `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// Report is the audit trail of a run. It is meant to be stored as JSON
+// and given back as Options.Resume to continue an interrupted run.
+type Report struct {
+	Identity  string    `json:"identity"`
+	DryRun    bool      `json:"dry_run"`
+	StartedAt time.Time `json:"started_at"`
+	Entries   []Entry   `json:"entries"`
+
+	// FinishedAt is nil until every room was searched.
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Count returns how many participants ended with the status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+type Options struct {
+	// The identity to anonymize.
+	Identity string
+
+	// Search only the rooms created on or after this date, given as YYYY-MM-DD.
+	DateCreatedAfter *string
+
+	// Search only the rooms created before this date, given as YYYY-MM-DD.
+	DateCreatedBefore *string
+
+	// Find the participants without anonymizing them.
+	DryRun bool
+
+	// The report of an earlier run. Its anonymized participants are kept in the new report
+	// and not sent again, its failed and pending ones are retried.
+	Resume *Report
+}
+
+// Identity anonymizes every participant with the identity in the completed rooms,
+// only disconnected participants can be anonymized.
+// A failure on a participant is recorded and the run goes on. An error listing rooms
+// or participants, or ctx being done, stops the run and returns the report so far with the error.
+func Identity(ctx context.Context, c Client, opts Options) (*Report, error) {
+	if opts.Identity == "" {
+		return nil, errors.New("Identity must not be empty")
+	}
+	if opts.Resume != nil && opts.Resume.Identity != opts.Identity {
+		return nil, errors.New("The report to resume is of another identity")
+	}
+
+	report := &Report{
+		Identity:  opts.Identity,
+		DryRun:    opts.DryRun,
+		StartedAt: time.Now(),
+	}
+	done := map[string]bool{}
+	if opts.Resume != nil {
+		for _, e := range opts.Resume.Entries {
+			if e.Status == StatusAnonymized {
+				report.Entries = append(report.Entries, e)
+				done[e.ParticipantSid] = true
+			}
+		}
+	}
+
+	status := rooms.RoomStatusCompleted
+	page, err := c.ListRooms(&rooms.RoomGetParams{
+		Status:            &status,
+		DateCreatedAfter:  opts.DateCreatedAfter,
+		DateCreatedBefore: opts.DateCreatedBefore,
+	})
+	for page != nil && err == nil {
+		for _, room := range page.Rooms {
+			if err := anonymizeRoom(ctx, c, room.Sid, opts, done, report); err != nil {
+				return report, err
+			}
+		}
+		page, err = c.NextRoomsPage(page)
+	}
+	if err != nil {
+		return report, err
+	}
+
+	finished := time.Now()
+	report.FinishedAt = &finished
+	return report, nil
+}
+
+func anonymizeRoom(
+	ctx context.Context,
+	c Client,
+	roomSid string,
+	opts Options,
+	done map[string]bool,
+	report *Report,
+) error {
+	identity := opts.Identity
+	page, err := c.ListParticipants(roomSid, &participants.ParticipantGetParams{Identity: &identity})
+	for page != nil && err == nil {
+		for _, p := range page.Participants {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if done[p.Sid] || p.Identity != opts.Identity {
+				continue
+			}
+
+			entry := Entry{RoomSid: roomSid, ParticipantSid: p.Sid, Status: StatusPending}
+			if !opts.DryRun {
+				if _, err := c.AnonymizeParticipant(roomSid, p.Sid); err != nil {
+					entry.Status = StatusFailed
+					entry.Error = err.Error()
+				} else {
+					entry.Status = StatusAnonymized
+					done[p.Sid] = true
+				}
+			}
+			entry.Time = time.Now()
+			report.Entries = append(report.Entries, entry)
+		}
+		page, err = c.NextParticipantsPage(page)
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package anonymize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matthxwpavin/twilio-compositions/video/participants"
+	"github.com/matthxwpavin/twilio-compositions/video/rooms"
+)
+
+// fakeClient serves one room per page and the participants of each room in a single page.
+type fakeClient struct {
+	rooms        []string
+	participants map[string][]participants.ParticipantInstance
+	failing      map[string]bool
+	anonymized   []string
+}
+
+func (c *fakeClient) roomPage(i int) *rooms.RoomInstanceList {
+	page := &rooms.RoomInstanceList{}
+	if i < len(c.rooms) {
+		page.Rooms = []rooms.RoomInstance{{Sid: c.rooms[i]}}
+	}
+	page.Meta.Page = i
+	if i+1 < len(c.rooms) {
+		page.Meta.NextPageURL = "next"
+	}
+	return page
+}
+
+func (c *fakeClient) ListRooms(param *rooms.RoomGetParams) (*rooms.RoomInstanceList, error) {
+	if param.Status == nil || *param.Status != rooms.RoomStatusCompleted {
+		return nil, errors.New("only completed rooms are expected")
+	}
+	return c.roomPage(0), nil
+}
+
+func (c *fakeClient) NextRoomsPage(page *rooms.RoomInstanceList) (*rooms.RoomInstanceList, error) {
+	if page.Meta.NextPageURL == "" {
+		return nil, nil
+	}
+	return c.roomPage(page.Meta.Page + 1), nil
+}
+
+func (c *fakeClient) ListParticipants(
+	roomSid string,
+	param *participants.ParticipantGetParams,
+) (*participants.ParticipantList, error) {
+	list := &participants.ParticipantList{}
+	for _, p := range c.participants[roomSid] {
+		if param.Identity == nil || *param.Identity == p.Identity {
+			list.Participants = append(list.Participants, p)
+		}
+	}
+	return list, nil
+}
+
+func (c *fakeClient) NextParticipantsPage(*participants.ParticipantList) (*participants.ParticipantList, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) AnonymizeParticipant(roomSid, participantSid string) (*participants.ParticipantInstance, error) {
+	if c.failing[participantSid] {
+		return nil, errors.New("participant is connected")
+	}
+	c.anonymized = append(c.anonymized, participantSid)
+	return &participants.ParticipantInstance{Sid: participantSid, Identity: participantSid}, nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		rooms: []string{"RM1", "RM2", "RM3"},
+		participants: map[string][]participants.ParticipantInstance{
+			"RM1": {{Sid: "PA1", Identity: "alice"}, {Sid: "PA2", Identity: "bob"}},
+			"RM2": {{Sid: "PA3", Identity: "bob"}},
+			"RM3": {{Sid: "PA4", Identity: "alice"}, {Sid: "PA5", Identity: "alice"}},
+		},
+		failing: map[string]bool{},
+	}
+}
+
+func statuses(r *Report) map[string]Status {
+	m := map[string]Status{}
+	for _, e := range r.Entries {
+		m[e.ParticipantSid] = e.Status
+	}
+	return m
+}
+
+func TestIdentityDryRun(t *testing.T) {
+	c := newFakeClient()
+	report, err := Identity(context.Background(), c, Options{Identity: "alice", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.anonymized) != 0 {
+		t.Errorf("dry run anonymized %v", c.anonymized)
+	}
+	if got := report.Count(StatusPending); got != 3 {
+		t.Errorf("got %d pending participants, want 3: %+v", got, report.Entries)
+	}
+	if report.FinishedAt == nil {
+		t.Error("finished run has no finish time")
+	}
+}
+
+func TestIdentityResume(t *testing.T) {
+	c := newFakeClient()
+	c.failing["PA4"] = true
+
+	first, err := Identity(context.Background(), c, Options{Identity: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := statuses(first)
+	if got["PA1"] != StatusAnonymized || got["PA4"] != StatusFailed || got["PA5"] != StatusAnonymized {
+		t.Fatalf("first run: %+v", first.Entries)
+	}
+
+	c.failing["PA4"] = false
+	c.anonymized = nil
+	second, err := Identity(context.Background(), c, Options{Identity: "alice", Resume: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.anonymized) != 1 || c.anonymized[0] != "PA4" {
+		t.Errorf("second run anonymized %v, want only the failed PA4", c.anonymized)
+	}
+	if n := second.Count(StatusAnonymized); n != 3 || len(second.Entries) != 3 {
+		t.Errorf("second run report: %+v", second.Entries)
+	}
+}
+
+func TestIdentityCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := Identity(ctx, newFakeClient(), Options{Identity: "alice"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if report == nil || report.FinishedAt != nil {
+		t.Fatalf("canceled run report: %+v", report)
+	}
+	b, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "finished_at") {
+		t.Errorf("unfinished report encodes finished_at: %s", b)
+	}
+}
+
+func TestIdentityResumeOtherIdentity(t *testing.T) {
+	_, err := Identity(context.Background(), newFakeClient(), Options{
+		Identity: "alice",
+		Resume:   &Report{Identity: "bob"},
+	})
+	if err == nil {
+		t.Error("resumed the report of another identity")
+	}
+}
@@ -0,0 +1,234 @@
+// Package archive copies the media of compositions and recordings to a storage
+// of our own, local files or an S3 compatible bucket, before Twilio deletes them.
+//
+// It reacts to the composition-available and recording-completed status callbacks,
+// or archives the media found by polling. Archiving is idempotent: media already
+// stored under its key with the expected size is not downloaded again,
+// so callbacks delivered twice and overlapping polls are harmless.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/media"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+	"github.com/matthxwpavin/twilio-compositions/video/rooms"
+)
+
+// Source fetches media metadata and content, implemented by the Twilio client.
+type Source interface {
+	GetComposition(compositionSid string) (*composition.Composition, error)
+	GetRecording(recordingSid string) (*recording.RecordingInstance, error)
+
+	// OpenComposition and OpenRecording return the media content,
+	// from Twilio or from its external location. The caller closes the reader.
+	OpenComposition(ctx context.Context, c *composition.Composition) (*media.Reader, error)
+	OpenRecording(ctx context.Context, rec *recording.RecordingInstance) (*media.Reader, error)
+}
+
+// Options of an Archiver.
+type Options struct {
+	// CompositionKey and RecordingKey are the key templates,
+	// DefaultCompositionKey and DefaultRecordingKey when empty.
+	CompositionKey string
+	RecordingKey   string
+}
+
+// Archiver copies media from the source to the storage.
+type Archiver struct {
+	source         Source
+	storage        Storage
+	compositionKey *Template
+	recordingKey   *Template
+}
+
+// NewArchiver returns an archiver of the source's media into the storage.
+func NewArchiver(source Source, storage Storage, opts Options) (*Archiver, error) {
+	if source == nil || storage == nil {
+		return nil, errors.New("Error, source and storage must not be nil.")
+	}
+	if opts.CompositionKey == "" {
+		opts.CompositionKey = DefaultCompositionKey
+	}
+	if opts.RecordingKey == "" {
+		opts.RecordingKey = DefaultRecordingKey
+	}
+	ck, err := ParseCompositionTemplate(opts.CompositionKey)
+	if err != nil {
+		return nil, err
+	}
+	rk, err := ParseRecordingTemplate(opts.RecordingKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Archiver{source: source, storage: storage, compositionKey: ck, recordingKey: rk}, nil
+}
+
+// Result is the outcome of archiving one media.
+type Result struct {
+	Sid  string
+	Key  string
+	Size int64
+
+	// Skipped is set when the media was already archived.
+	Skipped bool
+}
+
+// HandleCompositionCallback archives the composition of a composition-available callback.
+// Other events are ignored and return a nil result.
+func (a *Archiver) HandleCompositionCallback(ctx context.Context, p *composition.CallbackParam) (*Result, error) {
+	if p == nil || p.StatusCallbackEvent != composition.StatusCallbackAvailable {
+		return nil, nil
+	}
+	c, err := a.source.GetComposition(p.CompositionSid)
+	if err != nil {
+		return nil, err
+	}
+	return a.ArchiveComposition(ctx, c)
+}
+
+// HandleRecordingCallback archives the recording of a recording-completed callback.
+// Other events are ignored and return a nil result.
+func (a *Archiver) HandleRecordingCallback(ctx context.Context, p *recording.RecordingCallbackParameters) (*Result, error) {
+	if p == nil || p.StatusCallbackEvent != rooms.StatusRecCompleted {
+		return nil, nil
+	}
+	rec, err := a.source.GetRecording(p.RecordingSid)
+	if err != nil {
+		return nil, err
+	}
+	return a.ArchiveRecording(ctx, rec)
+}
+
+// ArchiveComposition copies a completed composition to the storage.
+func (a *Archiver) ArchiveComposition(ctx context.Context, c *composition.Composition) (*Result, error) {
+	if c == nil {
+		return nil, errors.New("Error, composition must not be nil.")
+	}
+	if c.Status != composition.StatusCompleted {
+		return nil, fmt.Errorf("Error, composition %s is %s, not completed.", c.Sid, c.Status)
+	}
+	return a.archive(ctx, c.Sid, a.compositionKey.CompositionKey(c), int64(c.Size), func() (*media.Reader, error) {
+		return a.source.OpenComposition(ctx, c)
+	})
+}
+
+// ArchiveRecording copies a completed recording to the storage.
+func (a *Archiver) ArchiveRecording(ctx context.Context, rec *recording.RecordingInstance) (*Result, error) {
+	if rec == nil {
+		return nil, errors.New("Error, recording must not be nil.")
+	}
+	if rec.Status != recording.StatusCompleted {
+		return nil, fmt.Errorf("Error, recording %s is %s, not completed.", rec.Sid, rec.Status)
+	}
+	return a.archive(ctx, rec.Sid, a.recordingKey.RecordingKey(rec), int64(rec.Size), func() (*media.Reader, error) {
+		return a.source.OpenRecording(ctx, rec)
+	})
+}
+
+// IsCompositionArchived reports whether the composition's media is in the storage with its expected size.
+func (a *Archiver) IsCompositionArchived(ctx context.Context, c *composition.Composition) (bool, error) {
+	_, ok, err := a.stored(ctx, a.compositionKey.CompositionKey(c), int64(c.Size))
+	return ok, err
+}
+
+// IsRecordingArchived reports whether the recording's media is in the storage with its expected size.
+func (a *Archiver) IsRecordingArchived(ctx context.Context, rec *recording.RecordingInstance) (bool, error) {
+	_, ok, err := a.stored(ctx, a.recordingKey.RecordingKey(rec), int64(rec.Size))
+	return ok, err
+}
+
+// stored returns the size of the object under the key, and whether it is a complete copy.
+// A size of zero means the metadata does not tell it, then any stored object counts.
+func (a *Archiver) stored(ctx context.Context, key string, size int64) (int64, bool, error) {
+	stored, ok, err := a.storage.Stat(ctx, key)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	return stored, size <= 0 || stored == size, nil
+}
+
+type openFunc func() (*media.Reader, error)
+
+// archive stores the media unless an object of the expected size is already under the key.
+func (a *Archiver) archive(ctx context.Context, sid, key string, size int64, open openFunc) (*Result, error) {
+	stored, ok, err := a.stored(ctx, key, size)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return &Result{Sid: sid, Key: key, Size: stored, Skipped: true}, nil
+	}
+
+	body, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	length := body.Size
+
+	if length < 0 && size > 0 {
+		length = size
+	}
+	if size > 0 && length != size {
+		return nil, fmt.Errorf("Error, media of %s has %d bytes, expected %d.", sid, length, size)
+	}
+	if err := a.storage.Put(ctx, key, body, length); err != nil {
+		return nil, err
+	}
+	return &Result{Sid: sid, Key: key, Size: length}, nil
+}
+
+// Failure is media that could not be archived.
+type Failure struct {
+	Sid string
+	Err error
+}
+
+// Report is the outcome of archiving a batch of media.
+type Report struct {
+	Archived []Result
+	Skipped  []Result
+	Failed   []Failure
+}
+
+// Archive copies the completed compositions and recordings, carrying on after failures
+// so one broken media does not hold back the others. Media that is not completed yet
+// is left for a later run. It stops early only when the context is done.
+func (a *Archiver) Archive(ctx context.Context, comps []composition.Composition, recs []recording.RecordingInstance) (*Report, error) {
+	report := &Report{}
+	add := func(sid string, res *Result, err error) {
+		switch {
+		case err != nil:
+			report.Failed = append(report.Failed, Failure{Sid: sid, Err: err})
+		case res.Skipped:
+			report.Skipped = append(report.Skipped, *res)
+		default:
+			report.Archived = append(report.Archived, *res)
+		}
+	}
+	for i := range comps {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if comps[i].Status != composition.StatusCompleted {
+			continue
+		}
+		res, err := a.ArchiveComposition(ctx, &comps[i])
+		add(comps[i].Sid, res, err)
+	}
+	for i := range recs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if recs[i].Status != recording.StatusCompleted {
+			continue
+		}
+		res, err := a.ArchiveRecording(ctx, &recs[i])
+		add(recs[i].Sid, res, err)
+	}
+	return report, nil
+}
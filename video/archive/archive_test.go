@@ -0,0 +1,222 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/media"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+	"github.com/matthxwpavin/twilio-compositions/video/rooms"
+)
+
+type fakeSource struct {
+	compositions map[string]*composition.Composition
+	recordings   map[string]*recording.RecordingInstance
+	media        map[string]string
+	opened       []string
+}
+
+func (s *fakeSource) GetComposition(sid string) (*composition.Composition, error) {
+	if c, ok := s.compositions[sid]; ok {
+		return c, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *fakeSource) GetRecording(sid string) (*recording.RecordingInstance, error) {
+	if rec, ok := s.recordings[sid]; ok {
+		return rec, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *fakeSource) open(sid string) (*media.Reader, error) {
+	m, ok := s.media[sid]
+	if !ok {
+		return nil, errors.New("no media")
+	}
+	s.opened = append(s.opened, sid)
+	return &media.Reader{ReadCloser: ioutil.NopCloser(strings.NewReader(m)), Size: int64(len(m))}, nil
+}
+
+func (s *fakeSource) OpenComposition(ctx context.Context, c *composition.Composition) (*media.Reader, error) {
+	return s.open(c.Sid)
+}
+
+func (s *fakeSource) OpenRecording(ctx context.Context, rec *recording.RecordingInstance) (*media.Reader, error) {
+	return s.open(rec.Sid)
+}
+
+func newSource() *fakeSource {
+	created := time.Date(2021, 6, 3, 10, 0, 0, 0, time.UTC)
+	rec := &recording.RecordingInstance{
+		Sid:             "RT1",
+		Status:          recording.StatusCompleted,
+		DateCreated:     created,
+		Size:            4,
+		Type:            recording.TypeAudio,
+		ContainerFormat: recording.ContainerMka,
+		TrackName:       "mic",
+	}
+	rec.GroupingSids.RoomSid = "RM1"
+	rec.GroupingSids.ParticipantSid = "PA1"
+	return &fakeSource{
+		compositions: map[string]*composition.Composition{
+			"CJ1": {Sid: "CJ1", RoomSid: "RM1", Status: composition.StatusCompleted, Format: composition.MediaFormatMP4, Size: 5, DateCreated: created},
+			"CJ2": {Sid: "CJ2", RoomSid: "RM1", Status: composition.StatusProcessing},
+		},
+		recordings: map[string]*recording.RecordingInstance{"RT1": rec},
+		media:      map[string]string{"CJ1": "video", "RT1": "mic!"},
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	src := newSource()
+	tmpl, err := ParseCompositionTemplate("{date}/{room}/{composition}.{format}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.CompositionKey(src.compositions["CJ1"]); got != "2021/06/03/RM1/CJ1.mp4" {
+		t.Errorf("got %s", got)
+	}
+	if got := tmpl.CompositionKey(&composition.Composition{Sid: "../x"}); got != "0001/01/01/_/.._x.webm" {
+		t.Errorf("got %s", got)
+	}
+
+	tmpl, err = ParseRecordingTemplate(DefaultRecordingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.RecordingKey(src.recordings["RT1"]); got != "RM1/PA1/RT1.mka" {
+		t.Errorf("got %s", got)
+	}
+
+	for _, bad := range []string{"", "{room", "{recording}.mp4"} {
+		if _, err := ParseCompositionTemplate(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestArchiveIsIdempotent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newSource()
+	a, err := NewArchiver(src, storage, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	comps := []composition.Composition{*src.compositions["CJ1"], *src.compositions["CJ2"]}
+	recs := []recording.RecordingInstance{*src.recordings["RT1"]}
+	report, err := a.Archive(ctx, comps, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Archived) != 2 || len(report.Skipped) != 0 || len(report.Failed) != 0 {
+		t.Fatalf("first run: %+v", report)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "RM1", "CJ1.mp4"))
+	if err != nil || string(b) != "video" {
+		t.Fatalf("composition file: %q, %v", b, err)
+	}
+
+	report, err = a.Archive(ctx, comps, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Archived) != 0 || len(report.Skipped) != 2 {
+		t.Fatalf("second run: %+v", report)
+	}
+	if len(src.opened) != 2 {
+		t.Errorf("media opened %d times", len(src.opened))
+	}
+
+	// A partial object from an interrupted copy is replaced.
+	if err := ioutil.WriteFile(filepath.Join(dir, "RM1", "PA1", "RT1.mka"), []byte("mi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := a.ArchiveRecording(ctx, src.recordings["RT1"])
+	if err != nil || res.Skipped {
+		t.Fatalf("got %+v, %v", res, err)
+	}
+}
+
+func TestArchiveCallbacks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := newSource()
+	a, err := NewArchiver(src, storage, Options{CompositionKey: "compositions/{composition}.{format}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	res, err := a.HandleCompositionCallback(ctx, &composition.CallbackParam{
+		CompositionSid:      "CJ1",
+		StatusCallbackEvent: composition.StatusCallbackProgress,
+	})
+	if res != nil || err != nil {
+		t.Fatalf("progress event: %+v, %v", res, err)
+	}
+	res, err = a.HandleCompositionCallback(ctx, &composition.CallbackParam{
+		CompositionSid:      "CJ1",
+		StatusCallbackEvent: composition.StatusCallbackAvailable,
+	})
+	if err != nil || res.Key != "compositions/CJ1.mp4" || res.Size != 5 {
+		t.Fatalf("got %+v, %v", res, err)
+	}
+	res, err = a.HandleRecordingCallback(ctx, &recording.RecordingCallbackParameters{
+		RecordingSid:        "RT1",
+		StatusCallbackEvent: rooms.StatusRecCompleted,
+	})
+	if err != nil || res.Key != "RM1/PA1/RT1.mka" {
+		t.Fatalf("got %+v, %v", res, err)
+	}
+	if _, err := a.HandleCompositionCallback(ctx, &composition.CallbackParam{
+		CompositionSid:      "CJ2",
+		StatusCallbackEvent: composition.StatusCallbackAvailable,
+	}); err == nil {
+		t.Error("expected an error for a composition that is not completed")
+	}
+}
+
+func TestLocalStorageSizeMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Put(context.Background(), "a/b.mka", strings.NewReader("abc"), 4); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, ok, _ := storage.Stat(context.Background(), "a/b.mka"); ok {
+		t.Error("a truncated copy was left behind")
+	}
+}
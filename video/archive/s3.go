@@ -0,0 +1,264 @@
+package archive
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/media"
+)
+
+// unsignedPayload lets the body stream without hashing it first, the connection is trusted to be TLS.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Storage stores media in a bucket of an S3 compatible service, such as AWS S3 or MinIO.
+// Requests use path style addressing and are signed with AWS Signature Version 4.
+type S3Storage struct {
+	// Endpoint of the service, for example https://s3.us-east-1.amazonaws.com.
+	Endpoint string
+
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Prefix is prepended to every key, for example "twilio/".
+	Prefix string
+
+	// Client sends the requests, http.DefaultClient when nil.
+	Client *http.Client
+
+	now func() time.Time
+}
+
+func (s *S3Storage) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return http.DefaultClient
+}
+
+func (s *S3Storage) objectURL(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	if s.Bucket == "" {
+		return "", fmt.Errorf("Error, S3 bucket must not be empty.")
+	}
+	return strings.TrimRight(s.Endpoint, "/") + "/" + uriEncode(s.Bucket, false) + "/" + uriEncode(s.Prefix+key, true), nil
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (int64, bool, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return 0, false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.ContentLength, true, nil
+	case http.StatusNotFound:
+		return 0, false, nil
+	}
+	return 0, false, fmt.Errorf("Error, S3 HEAD %s: %s", key, resp.Status)
+}
+
+// Put uploads the object with a single PUT, S3 only stores it once the whole body is received.
+// A reader of unknown size is spooled to a temporary file first, since PUT needs the length.
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		f, err := ioutil.TempFile("", "archive-*.tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+
+		if size, err = io.Copy(f, &contextReader{ctx: ctx, r: r}); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r = f
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, ioutil.NopCloser(r))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Error, S3 PUT %s: %s %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Fetch reads the object at the location, a URL of the bucket in either path or virtual hosted style,
+// such as the media_external_location of media Twilio uploaded there. It makes S3Storage a media.Fetcher.
+func (s *S3Storage) Fetch(ctx context.Context, location string) (*media.Reader, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := media.FromResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	r.External = true
+	return r, nil
+}
+
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	req.Header.Set("x-amz-content-sha256", unsignedPayload)
+	s.sign(req, now().UTC())
+	return s.client().Do(req)
+}
+
+// sign adds the Authorization header of Signature Version 4. The host, range, content type
+// and every x-amz-* header are signed, the payload hash is taken from x-amz-content-sha256.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3Storage) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "range" || name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req),
+		canonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("x-amz-content-sha256"),
+	}, "\n")
+
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), day)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalURI(req *http.Request) string {
+	p := req.URL.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+func canonicalQuery(req *http.Request) string {
+	q := req.URL.Query()
+	var keys []string
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		values := q[k]
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, false)+"="+uriEncode(v, false))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes every byte but the unreserved characters, and the slash when keepSlash is set.
+func uriEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,110 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage keeps archived media under keys made of slash separated segments.
+type Storage interface {
+	// Stat returns the size of the object stored under the key, and false when there is none.
+	Stat(ctx context.Context, key string) (int64, bool, error)
+
+	// Put stores the content of the reader under the key, replacing any object.
+	// The size is -1 when it is unknown. An object must never be left half written.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+}
+
+// LocalStorage stores media as files under a directory.
+type LocalStorage struct {
+	Dir string
+}
+
+// NewLocalStorage returns a storage that keeps the files under the directory, creating it if needed.
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{Dir: dir}, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, key string) (int64, bool, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return 0, false, err
+	}
+	fi, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return fi.Size(), true, nil
+}
+
+// Put writes to a temporary file next to the target and renames it once complete.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(p), "."+filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, &contextReader{ctx: ctx, r: r})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("Error, wrote %d bytes of %d for key %s.", n, size, key)
+	}
+	return os.Rename(f.Name(), p)
+}
+
+// checkKey rejects keys that would escape the storage's root.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("Error, invalid storage key %q.", key)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("Error, invalid storage key %q.", key)
+		}
+	}
+	return nil
+}
+
+// contextReader stops reading once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
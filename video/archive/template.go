@@ -0,0 +1,139 @@
+package archive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+const (
+	DefaultCompositionKey = "{room}/{composition}.{format}"
+	DefaultRecordingKey   = "{room}/{participant}/{recording}.{container}"
+)
+
+// compositionFields and recordingFields are the placeholders each kind of media fills.
+var (
+	compositionFields = map[string]bool{"room": true, "composition": true, "format": true, "date": true}
+	recordingFields   = map[string]bool{
+		"room":        true,
+		"participant": true,
+		"recording":   true,
+		"track":       true,
+		"type":        true,
+		"container":   true,
+		"date":        true,
+	}
+)
+
+// Template makes storage keys out of a pattern with placeholders in braces, such as
+// {room}/{composition}.{format}. Compositions fill {room}, {composition}, {format} and {date},
+// recordings fill {room}, {participant}, {recording}, {track}, {type}, {container} and {date}.
+// {date} is the creation date of the media as YYYY/MM/DD.
+type Template struct {
+	pattern string
+	parts   []templatePart
+}
+
+type templatePart struct {
+	literal string
+	field   string
+}
+
+// ParseCompositionTemplate parses a template for composition keys.
+func ParseCompositionTemplate(pattern string) (*Template, error) {
+	return parseTemplate(pattern, compositionFields)
+}
+
+// ParseRecordingTemplate parses a template for recording keys.
+func ParseRecordingTemplate(pattern string) (*Template, error) {
+	return parseTemplate(pattern, recordingFields)
+}
+
+func parseTemplate(pattern string, fields map[string]bool) (*Template, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("Error, the key template must not be empty.")
+	}
+	t := &Template{pattern: pattern}
+	for rest := pattern; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("Error, unclosed placeholder in key template %q.", pattern)
+		}
+		field := rest[open+1 : open+end]
+		if !fields[field] {
+			return nil, fmt.Errorf("Error, unknown placeholder {%s} in key template %q.", field, pattern)
+		}
+		if open > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:open]})
+		}
+		t.parts = append(t.parts, templatePart{field: field})
+		rest = rest[open+end+1:]
+	}
+	return t, nil
+}
+
+func (t *Template) String() string {
+	return t.pattern
+}
+
+// CompositionKey returns the key of the composition.
+func (t *Template) CompositionKey(c *composition.Composition) string {
+	return t.expand(map[string]string{
+		"room":        c.RoomSid,
+		"composition": c.Sid,
+		"format":      string(formatOf(c)),
+		"date":        date(c.DateCreated),
+	})
+}
+
+// RecordingKey returns the key of the recording.
+func (t *Template) RecordingKey(rec *recording.RecordingInstance) string {
+	return t.expand(map[string]string{
+		"room":        rec.GroupingSids.RoomSid,
+		"participant": rec.GroupingSids.ParticipantSid,
+		"recording":   rec.Sid,
+		"track":       rec.TrackName,
+		"type":        string(rec.Type),
+		"container":   string(rec.ContainerFormat),
+		"date":        date(rec.DateCreated),
+	})
+}
+
+func (t *Template) expand(values map[string]string) string {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.field == "" {
+			b.WriteString(p.literal)
+			continue
+		}
+		v := values[p.field]
+		if p.field != "date" {
+			// Values end up in paths, they must not add or climb directories.
+			v = strings.Replace(v, "/", "_", -1)
+			if v == "" || v == "." || v == ".." {
+				v = "_"
+			}
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+func date(t time.Time) string {
+	return t.UTC().Format("2006/01/02")
+}
+
+func formatOf(c *composition.Composition) composition.MediaFormat {
+	if c.Format == "" {
+		return composition.DefaultMediaFormat
+	}
+	return c.Format
+}
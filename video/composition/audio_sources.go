@@ -0,0 +1,46 @@
+package composition
+
+import (
+	"net/url"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+// AudioSourcer is implemented by the params that mix audio tracks, ComposeParams and HooksParams.
+type AudioSourcer interface {
+	GetAudioSources() []string
+	GetAudioSourcesExcluded() []string
+}
+
+// AddAudioSources adds the audio sources of the params to the request's form values.
+// Lists are sent by repeating the key, which the form encoder does not do.
+func AddAudioSources(values url.Values, p AudioSourcer) {
+	for _, s := range p.GetAudioSources() {
+		values.Add("AudioSources", s)
+	}
+	for _, s := range p.GetAudioSourcesExcluded() {
+		values.Add("AudioSourcesExcluded", s)
+	}
+}
+
+// IncludedAudioTracks returns the track names, in the given order, that the params would mix.
+// A track is included when it matches an entry of audio_sources and none of audio_sources_excluded,
+// entries can use the asterisk as a wildcard. Without audio_sources no track is included.
+func IncludedAudioTracks(p AudioSourcer, trackNames []string) []string {
+	var included []string
+	for _, name := range trackNames {
+		if matchAny(p.GetAudioSources(), name) && !matchAny(p.GetAudioSourcesExcluded(), name) {
+			included = append(included, name)
+		}
+	}
+	return included
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if video.MatchSource(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
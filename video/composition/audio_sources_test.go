@@ -0,0 +1,65 @@
+package composition
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ajg/form"
+)
+
+func TestIncludedAudioTracks(t *testing.T) {
+	tracks := []string{"teacher", "studentA", "studentTeam", "studentTA", "screen-audio"}
+	tests := []struct {
+		sources, excluded []string
+		want              []string
+	}{
+		{nil, nil, nil},
+		{[]string{"*"}, nil, tracks},
+		{[]string{"student*"}, []string{"*TA"}, []string{"studentA", "studentTeam"}},
+		{[]string{"teacher", "*Team"}, nil, []string{"teacher", "studentTeam"}},
+		{[]string{"*"}, []string{"student*", "screen*"}, []string{"teacher"}},
+	}
+	for _, tt := range tests {
+		got := IncludedAudioTracks(&ComposeParams{
+			AudioSources:         tt.sources,
+			AudioSourcesExcluded: tt.excluded,
+		}, tracks)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sources %v excluding %v: got %v, want %v", tt.sources, tt.excluded, got, tt.want)
+		}
+	}
+}
+
+func TestAddAudioSources(t *testing.T) {
+	for _, p := range []AudioSourcer{
+		&ComposeParams{
+			RoomSid:              "RM1",
+			AudioSources:         []string{"teacher", "student*"},
+			AudioSourcesExcluded: []string{"student-muted"},
+		},
+		&HooksParams{
+			FriendlyName:         "hooks",
+			AudioSources:         []string{"teacher", "student*"},
+			AudioSourcesExcluded: []string{"student-muted"},
+		},
+	} {
+		values, err := form.EncodeToValues(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		AddAudioSources(values, p)
+		body := values.Encode()
+		for _, want := range []string{
+			"AudioSources=teacher&AudioSources=student%2A",
+			"AudioSourcesExcluded=student-muted",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("%T: body %s does not contain %s", p, body, want)
+			}
+		}
+		if got := values["AudioSources"]; len(got) != 2 || got[0] != "teacher" || got[1] != "student*" {
+			t.Errorf("%T: AudioSources %v", p, got)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package composition
 
 import (
-	"github.com/matthxwpavin/twilio-compositions/video"
+	"encoding/json"
 	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
 )
 
 type CompStatus string
@@ -16,26 +18,45 @@ const (
 )
 
 type Composition struct {
-	AccountSid           string     `json:"account_sid"`
-	AudioSources         []string   `json:"audio_sources"`
-	AudioSourcesExcluded []string   `json:"audio_sources_excluded"`
-	Bitrate              int        `json:"bitrate"`
-	DateCompleted        time.Time  `json:"date_completed"`
-	DateCreated          time.Time  `json:"date_created"`
-	DateDeleted          *time.Time `json:"date_deleted"`
-	Duration             int        `json:"duration"`
-	Format               string     `json:"format"`
+	AccountSid           string      `json:"account_sid"`
+	AudioSources         []string    `json:"audio_sources"`
+	AudioSourcesExcluded []string    `json:"audio_sources_excluded"`
+	Bitrate              int         `json:"bitrate"`
+	DateCompleted        *time.Time  `json:"date_completed"`
+	DateCreated          time.Time   `json:"date_created"`
+	DateDeleted          *time.Time  `json:"date_deleted"`
+	Duration             int         `json:"duration"`
+	Format               MediaFormat `json:"format"`
 	Links                struct {
 		Media string `json:"media"`
 	} `json:"links"`
-	Resolution  string                 `json:"resolution"`
-	RoomSid     string                 `json:"room_sid"`
-	Sid         string                 `json:"sid"`
-	Size        int                    `json:"size"`
-	Status      string                 `json:"status"`
-	Trim        bool                   `json:"trim"`
-	URL         string                 `json:"url"`
-	VideoLayout map[string]interface{} `json:"video_layout"`
+	MediaExternalLocation string             `json:"media_external_location"`
+	Resolution            video.Resolution   `json:"resolution"`
+	RoomSid               string             `json:"room_sid"`
+	Sid                   string             `json:"sid"`
+	Size                  int                `json:"size"`
+	Status                CompStatus         `json:"status"`
+	Trim                  bool               `json:"trim"`
+	URL                   string             `json:"url"`
+	VideoLayout           *video.VideoLayout `json:"video_layout"`
+}
+
+// UnmarshalJSON decodes the composition and gives its video layout the composition's resolution,
+// so the layout's regions can be validated and drawn like a layout made with video.NewVideoLayout.
+func (c *Composition) UnmarshalJSON(data []byte) error {
+	type fields Composition
+	if err := json.Unmarshal(data, (*fields)(c)); err != nil {
+		return err
+	}
+	if c.VideoLayout != nil {
+		c.VideoLayout.Resolution = c.Resolution
+	}
+	return nil
+}
+
+// GetDuration returns the duration of the composed media.
+func (c *Composition) GetDuration() time.Duration {
+	return time.Duration(c.Duration) * time.Second
 }
 
 type CompositionList struct {
@@ -64,6 +85,31 @@ var (
 	WebM = retFormatFunc("webm")
 )
 
+// MediaFormat is the container format of a composition as given in responses.
+type MediaFormat string
+
+const (
+	MediaFormatMP4  MediaFormat = "mp4"
+	MediaFormatWebM MediaFormat = "webm"
+)
+
+// DefaultMediaFormat is the format Twilio uses when none is given.
+const DefaultMediaFormat = MediaFormatWebM
+
+// Param returns the format as a request parameter.
+func (f MediaFormat) Param() Format {
+	return retFormatFunc(string(f))
+}
+
+// MediaFormatOf returns the format of a request parameter,
+// DefaultMediaFormat when it is not given.
+func MediaFormatOf(f Format) MediaFormat {
+	if f == nil {
+		return DefaultMediaFormat
+	}
+	return MediaFormat(*f)
+}
+
 type ComposeParams struct {
 
 	// The SID of the Group Room with the media tracks to be used as composition sources.
@@ -80,14 +126,14 @@ type ComposeParams struct {
 	// The track names in this parameter can include an asterisk as a wild card character,
 	// which matches zero or more characters in a track name. For example,
 	// student* includes tracks named student as well as studentTeam.
-	AudioSources *string `form:"AudioSources,omitempty"`
+	AudioSources []string `form:"-"`
 
 	// An array of track names to exclude. A composition triggered by the composition hook
 	// includes all audio sources specified in audio_sources except
 	// for those specified in audio_sources_excluded. The track names in this parameter can include
 	// an asterisk as a wild card character, which matches zero or more characters in a track name.
 	// For example, student* excludes student as well as studentTeam. This parameter can also be empty.
-	AudioSourcesExcluded *string `form:"AudioSourcesExcluded,omitempty"`
+	AudioSourcesExcluded []string `form:"-"`
 
 	// A string that describes the columns (width) and rows (height)
 	// of the generated composed video in pixels.
@@ -95,7 +141,7 @@ type ComposeParams struct {
 	// 16 <= {width} <= 1280
 	// 16 <= {height} <= 1280
 	// {width} * {height} <= 921,600
-	Resolution *string `form:"Resolution,omitempty"`
+	Resolution *video.Resolution `form:"Resolution,omitempty"`
 
 	// The container format of the media files used by the compositions
 	// created by the composition hook. Can be: mp4 or webm and the default is webm.
@@ -123,10 +169,18 @@ func (p *ComposeParams) GetVideoLayout() *video.VideoLayout {
 	return p.VideoLayout
 }
 
-func (p *ComposeParams) GetResolution() *string {
+func (p *ComposeParams) GetResolution() *video.Resolution {
 	return p.Resolution
 }
 
+func (p *ComposeParams) GetAudioSources() []string {
+	return p.AudioSources
+}
+
+func (p *ComposeParams) GetAudioSourcesExcluded() []string {
+	return p.AudioSourcesExcluded
+}
+
 type GetParams struct {
 	// Read only Composition resources with this status.
 	// Can be: enqueued, processing, completed, deleted, or failed.
@@ -1,40 +1,106 @@
 package composition
 
 import (
-	"github.com/matthxwpavin/twilio-compositions/video"
+	"encoding/json"
 	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
 )
 
 // More info https://www.twilio.com/docs/video/api/composition-hooks
 
 type CompositionHooks struct {
-	AccountSid           string                 `json:"account_sid"`
-	Sid                  string                 `json:"sid"`
-	FriendlyName         string                 `json:"friendly_name"`
-	Enabled              bool                   `json:"enabled"`
-	DateCreated          time.Time              `json:"date_created"`
-	DateUpdated          *time.Time             `json:"date_updated"`
-	AudioSources         []string               `json:"audio_sources"`
-	AudioSourcesExcluded []string               `json:"audio_sources_excluded"`
-	VideoLayout          map[string]interface{} `json:"video_layout"`
-	Format               string                 `json:"format"`
-	Trim                 bool                   `json:"trim"`
-	URL                  string                 `json:"url"`
-	Resolution           string                 `json:"resolution"`
-	StatusCallbackMethod string                 `json:"status_callback_method"`
-	StatusCallback       string                 `json:"status_callback"`
+	AccountSid           string             `json:"account_sid"`
+	Sid                  string             `json:"sid"`
+	FriendlyName         string             `json:"friendly_name"`
+	Enabled              bool               `json:"enabled"`
+	DateCreated          time.Time          `json:"date_created"`
+	DateUpdated          *time.Time         `json:"date_updated"`
+	AudioSources         []string           `json:"audio_sources"`
+	AudioSourcesExcluded []string           `json:"audio_sources_excluded"`
+	VideoLayout          *video.VideoLayout `json:"video_layout"`
+	Format               MediaFormat        `json:"format"`
+	Trim                 bool               `json:"trim"`
+	URL                  string             `json:"url"`
+	Resolution           video.Resolution   `json:"resolution"`
+	StatusCallbackMethod string             `json:"status_callback_method"`
+	StatusCallback       string             `json:"status_callback"`
+}
+
+// UnmarshalJSON decodes the composition hooks and gives its video layout the hooks' resolution,
+// so the layout's regions can be validated and drawn like a layout made with video.NewVideoLayout.
+func (h *CompositionHooks) UnmarshalJSON(data []byte) error {
+	type fields CompositionHooks
+	if err := json.Unmarshal(data, (*fields)(h)); err != nil {
+		return err
+	}
+	if h.VideoLayout != nil {
+		h.VideoLayout.Resolution = h.Resolution
+	}
+	return nil
+}
+
+// ToParams returns the parameters that would create the composition hooks as they are,
+// so a fetched hooks can be compared to the desired HooksParams.
+func (h *CompositionHooks) ToParams() *HooksParams {
+	enabled := h.Enabled
+	trim := h.Trim
+	p := &HooksParams{
+		FriendlyName: h.FriendlyName,
+		Enabled:      &enabled,
+		VideoLayout:  h.VideoLayout,
+		Format:       h.Format.Param(),
+		Trim:         &trim,
+	}
+	if !h.Resolution.IsZero() {
+		resolution := h.Resolution
+		p.Resolution = &resolution
+	}
+	if len(h.AudioSources) > 0 {
+		p.AudioSources = append([]string{}, h.AudioSources...)
+	}
+	if len(h.AudioSourcesExcluded) > 0 {
+		p.AudioSourcesExcluded = append([]string{}, h.AudioSourcesExcluded...)
+	}
+	if h.StatusCallback != "" {
+		callback := h.StatusCallback
+		p.StatusCallBack = &callback
+	}
+	if h.StatusCallbackMethod != "" {
+		method := h.StatusCallbackMethod
+		p.StatusCallBackMethod = &method
+	}
+	return p
 }
 
 type CompositionHooksList struct {
 	CompositionHooks []CompositionHooks `json:"composition_hooks"`
+	Meta             Meta               `json:"meta"`
 }
 
-const (
-	HD  = "1280x720"
-	PAL = "1024x576"
-	VGA = "640x480"
-	CIF = "320x240"
-)
+type HooksGetParams struct {
+	// Read only CompositionHook resources with an enabled value that matches this parameter.
+	Enabled *bool `form:"Enabled,omitempty"`
+
+	// Read only CompositionHook resources created on or after this ISO 8601 date-time with time zone.
+	DateCreatedAfter *string `form:"DateCreatedAfter,omitempty"`
+
+	// Read only CompositionHook resources created before this ISO 8601 date-time with time zone.
+	DateCreatedBefore *string `form:"DateCreatedBefore,omitempty"`
+
+	// Read only CompositionHook resources with friendly names that match this string.
+	// The match is not case sensitive and can include asterisk * characters as wildcard match.
+	FriendlyName *string `form:"FriendlyName,omitempty"`
+
+	// How many resources to return in each list page. The default is 50, and the maximum is 1000.
+	PageSize *uint `form:"PageSize,omitempty"`
+}
+
+// Presets of common resolutions, the same as the video package's.
+func HD() video.Resolution  { return video.HD() }
+func PAL() video.Resolution { return video.PAL() }
+func VGA() video.Resolution { return video.VGA() }
+func CIF() video.Resolution { return video.CIF() }
 
 type HooksParams struct {
 	// A descriptive string that you create to describe the resource.
@@ -57,14 +123,14 @@ type HooksParams struct {
 	// The track names in this parameter can include an asterisk as a wild card character,
 	// which matches zero or more characters in a track name. For example,
 	// student* includes tracks named student as well as studentTeam.
-	AudioSources *string `form:"AudioSources,omitempty"`
+	AudioSources []string `form:"-"`
 
 	// An array of track names to exclude. A composition triggered by the composition hook
 	// includes all audio sources specified in audio_sources except
 	// for those specified in audio_sources_excluded. The track names in this parameter can include
 	// an asterisk as a wild card character, which matches zero or more characters in a track name.
 	// For example, student* excludes student as well as studentTeam. This parameter can also be empty.
-	AudioSourcesExcluded *string `form:"AudioSourcesExcluded,omitempty"`
+	AudioSourcesExcluded []string `form:"-"`
 
 	// A string that describes the columns (width) and rows (height)
 	// of the generated composed video in pixels.
@@ -72,7 +138,7 @@ type HooksParams struct {
 	// 16 <= {width} <= 1280
 	// 16 <= {height} <= 1280
 	// {width} * {height} <= 921,600
-	Resolution *string `form:"Resolution,omitempty"`
+	Resolution *video.Resolution `form:"Resolution,omitempty"`
 
 	// The container format of the media files used by the compositions
 	// created by the composition hook. Can be: mp4 or webm and the default is webm.
@@ -100,6 +166,14 @@ func (p *HooksParams) GetVideoLayout() *video.VideoLayout {
 	return p.VideoLayout
 }
 
-func (p *HooksParams) GetResolution() *string {
+func (p *HooksParams) GetResolution() *video.Resolution {
 	return p.Resolution
 }
+
+func (p *HooksParams) GetAudioSources() []string {
+	return p.AudioSources
+}
+
+func (p *HooksParams) GetAudioSourcesExcluded() []string {
+	return p.AudioSourcesExcluded
+}
@@ -0,0 +1,82 @@
+package composition
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+const compositionResponse = `{
+	"sid": "CJXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+	"status": "processing",
+	"format": "mp4",
+	"resolution": "1280x720",
+	"duration": 95,
+	"date_completed": null,
+	"date_created": "2021-05-18T10:00:00Z",
+	"video_layout": {"grid": {"x_pos": 640, "video_sources": ["*"]}}
+}`
+
+func TestCompositionTypedFields(t *testing.T) {
+	var c Composition
+	if err := json.Unmarshal([]byte(compositionResponse), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != StatusProcessing || c.Format != MediaFormatMP4 || c.Resolution != video.HD() {
+		t.Errorf("got status %q, format %q, resolution %v", c.Status, c.Format, c.Resolution)
+	}
+	if c.GetDuration() != 95*time.Second {
+		t.Errorf("got duration %v", c.GetDuration())
+	}
+	if c.DateCompleted != nil {
+		t.Errorf("date completed must be nil, got %v", c.DateCompleted)
+	}
+	if c.VideoLayout.GetResolution() != video.HD() {
+		t.Errorf("layout must take the composition's resolution, got %v", c.VideoLayout.GetResolution())
+	}
+	if err := c.VideoLayout.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAudioOnlyComposition(t *testing.T) {
+	var c Composition
+	if err := json.Unmarshal([]byte(`{"resolution": "", "video_layout": {}}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Resolution.IsZero() || len(c.VideoLayout.GetRegions()) != 0 {
+		t.Errorf("got resolution %v and %d regions", c.Resolution, len(c.VideoLayout.GetRegions()))
+	}
+}
+
+func TestCompositionHooksToParams(t *testing.T) {
+	var h CompositionHooks
+	if err := json.Unmarshal([]byte(`{
+		"friendly_name": "classroom",
+		"enabled": true,
+		"format": "webm",
+		"resolution": "640x480",
+		"trim": true,
+		"audio_sources": ["teacher"],
+		"status_callback": "https://example.com/callback",
+		"video_layout": {"grid": {"video_sources": ["*"]}}
+	}`), &h); err != nil {
+		t.Fatal(err)
+	}
+
+	p := h.ToParams()
+	if p.FriendlyName != "classroom" || !*p.Enabled || !*p.Trim {
+		t.Errorf("got %+v", p)
+	}
+	if MediaFormatOf(p.Format) != MediaFormatWebM || *p.Resolution != video.VGA() {
+		t.Errorf("got format %v and resolution %v", MediaFormatOf(p.Format), *p.Resolution)
+	}
+	if len(p.AudioSources) != 1 || p.AudioSources[0] != "teacher" || *p.StatusCallBack != "https://example.com/callback" {
+		t.Errorf("got audio sources %v, callback %v", p.AudioSources, *p.StatusCallBack)
+	}
+	if p.GetVideoLayout().GetResolution() != *p.GetResolution() {
+		t.Error("params must pass the resolution check against its layout")
+	}
+}
@@ -0,0 +1,222 @@
+package composition
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+// SourcesDiff lists the track names added to or removed from a list of audio sources.
+type SourcesDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// ParamsDiff is the semantic difference between two HooksParams or two ComposeParams.
+// It is encoded as JSON for code review tools and as text by String.
+type ParamsDiff struct {
+	Changes              []video.Change    `json:"changes,omitempty"`
+	AudioSources         *SourcesDiff      `json:"audio_sources,omitempty"`
+	AudioSourcesExcluded *SourcesDiff      `json:"audio_sources_excluded,omitempty"`
+	VideoLayout          *video.LayoutDiff `json:"video_layout,omitempty"`
+}
+
+// DiffHooksParams compares the parameters with Twilio's defaults filled in,
+// so a missing Format equals webm and a missing Trim equals true.
+// Use CompositionHooks.ToParams to compare an existing hooks with the desired one.
+func DiffHooksParams(before, after *HooksParams) *ParamsDiff {
+	if before == nil {
+		before = &HooksParams{}
+	}
+	if after == nil {
+		after = &HooksParams{}
+	}
+
+	d := diffCommon(before.common(), after.common())
+	var changes []video.Change
+	if before.FriendlyName != after.FriendlyName {
+		changes = append(changes, video.Change{
+			Property: "friendly_name",
+			Before:   before.FriendlyName,
+			After:    after.FriendlyName,
+		})
+	}
+	if b, a := boolOr(before.Enabled, true), boolOr(after.Enabled, true); b != a {
+		changes = append(changes, video.Change{Property: "enabled", Before: b, After: a})
+	}
+	d.Changes = append(changes, d.Changes...)
+	return d
+}
+
+// DiffComposeParams compares the parameters with Twilio's defaults filled in,
+// so a missing Format equals webm and a missing Trim equals true.
+func DiffComposeParams(before, after *ComposeParams) *ParamsDiff {
+	if before == nil {
+		before = &ComposeParams{}
+	}
+	if after == nil {
+		after = &ComposeParams{}
+	}
+
+	d := diffCommon(before.common(), after.common())
+	if before.RoomSid != after.RoomSid {
+		d.Changes = append([]video.Change{{
+			Property: "room_sid",
+			Before:   before.RoomSid,
+			After:    after.RoomSid,
+		}}, d.Changes...)
+	}
+	return d
+}
+
+// Empty reports whether both parameters are equivalent.
+func (d *ParamsDiff) Empty() bool {
+	return len(d.Changes) == 0 &&
+		d.AudioSources == nil &&
+		d.AudioSourcesExcluded == nil &&
+		(d.VideoLayout == nil || d.VideoLayout.Empty())
+}
+
+func (d *ParamsDiff) String() string {
+	var b bytes.Buffer
+	for _, c := range d.Changes {
+		fmt.Fprintf(&b, "%s\n", c)
+	}
+	writeSourcesDiff(&b, "audio_sources", d.AudioSources)
+	writeSourcesDiff(&b, "audio_sources_excluded", d.AudioSourcesExcluded)
+	if d.VideoLayout != nil && !d.VideoLayout.Empty() {
+		b.WriteString("video_layout:\n")
+		for _, line := range bytes.SplitAfter([]byte(d.VideoLayout.String()), []byte("\n")) {
+			if len(line) > 0 {
+				b.WriteString("  ")
+				b.Write(line)
+			}
+		}
+	}
+	return b.String()
+}
+
+func writeSourcesDiff(b *bytes.Buffer, name string, d *SourcesDiff) {
+	if d == nil {
+		return
+	}
+	for _, s := range d.Added {
+		fmt.Fprintf(b, "%s: + %q\n", name, s)
+	}
+	for _, s := range d.Removed {
+		fmt.Fprintf(b, "%s: - %q\n", name, s)
+	}
+}
+
+// commonParams are the parameters shared by HooksParams and ComposeParams.
+type commonParams struct {
+	layout               *video.VideoLayout
+	audioSources         []string
+	audioSourcesExcluded []string
+	resolution           video.Resolution
+	format               MediaFormat
+	trim                 bool
+	statusCallback       string
+	statusCallbackMethod string
+}
+
+func (p *HooksParams) common() commonParams {
+	return commonParams{
+		layout:               p.VideoLayout,
+		audioSources:         p.AudioSources,
+		audioSourcesExcluded: p.AudioSourcesExcluded,
+		resolution:           resolutionOr(p.Resolution),
+		format:               MediaFormatOf(p.Format),
+		trim:                 boolOr(p.Trim, true),
+		statusCallback:       stringOr(p.StatusCallBack, ""),
+		statusCallbackMethod: stringOr(p.StatusCallBackMethod, "POST"),
+	}
+}
+
+func (p *ComposeParams) common() commonParams {
+	return commonParams{
+		layout:               p.VideoLayout,
+		audioSources:         p.AudioSources,
+		audioSourcesExcluded: p.AudioSourcesExcluded,
+		resolution:           resolutionOr(p.Resolution),
+		format:               MediaFormatOf(p.Format),
+		trim:                 boolOr(p.Trim, true),
+		statusCallback:       stringOr(p.StatusCallback, ""),
+		statusCallbackMethod: stringOr(p.StatusCallbackMethod, "POST"),
+	}
+}
+
+func diffCommon(before, after commonParams) *ParamsDiff {
+	d := &ParamsDiff{
+		AudioSources:         diffSources(before.audioSources, after.audioSources),
+		AudioSourcesExcluded: diffSources(before.audioSourcesExcluded, after.audioSourcesExcluded),
+	}
+	if ld := video.DiffLayouts(before.layout, after.layout); !ld.Empty() {
+		d.VideoLayout = ld
+	}
+
+	add := func(property string, b, a interface{}) {
+		if b != a {
+			d.Changes = append(d.Changes, video.Change{Property: property, Before: b, After: a})
+		}
+	}
+	add("resolution", before.resolution.String(), after.resolution.String())
+	add("format", string(before.format), string(after.format))
+	add("trim", before.trim, after.trim)
+	add("status_callback", before.statusCallback, after.statusCallback)
+	add("status_callback_method", before.statusCallbackMethod, after.statusCallbackMethod)
+	return d
+}
+
+// diffSources compares the lists as sets, their order does not matter to Twilio.
+func diffSources(before, after []string) *SourcesDiff {
+	in := func(list []string, s string) bool {
+		for _, v := range list {
+			if v == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	d := &SourcesDiff{}
+	for _, s := range after {
+		if !in(before, s) && !in(d.Added, s) {
+			d.Added = append(d.Added, s)
+		}
+	}
+	for _, s := range before {
+		if !in(after, s) && !in(d.Removed, s) {
+			d.Removed = append(d.Removed, s)
+		}
+	}
+	if len(d.Added) == 0 && len(d.Removed) == 0 {
+		return nil
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	return d
+}
+
+func resolutionOr(r *video.Resolution) video.Resolution {
+	if r == nil {
+		return video.DefaultResolution()
+	}
+	return *r
+}
+
+func boolOr(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
+
+func stringOr(s *string, def string) string {
+	if s == nil {
+		return def
+	}
+	return *s
+}
@@ -0,0 +1,92 @@
+package composition
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+func TestDiffHooksParamsIgnoresNoise(t *testing.T) {
+	var existing CompositionHooks
+	if err := json.Unmarshal([]byte(`{
+		"friendly_name": "classroom",
+		"enabled": true,
+		"format": "webm",
+		"resolution": "640x480",
+		"trim": true,
+		"status_callback_method": "POST",
+		"video_layout": {"grid": {"video_sources_excluded": ["b", "a"], "x_pos": 0,
+			"reuse": "show_oldest", "width": 640, "video_sources": ["*"], "cells_excluded": [3, 1]}}
+	}`), &existing); err != nil {
+		t.Fatal(err)
+	}
+
+	desired, err := video.NewVideoLayout(video.VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := desired.NewRegion("grid").
+		Sources("*").
+		ExcludeSources("a", "b").
+		ExcludeCells(1, 3).
+		Add(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := DiffHooksParams(existing.ToParams(), &HooksParams{
+		FriendlyName: "classroom",
+		VideoLayout:  desired,
+	})
+	if !d.Empty() {
+		t.Errorf("expected no difference, got:\n%s", d)
+	}
+}
+
+func TestDiffHooksParams(t *testing.T) {
+	before, err := video.NewVideoLayout(video.VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	before.NewRegion("grid").Sources("*").Add()
+	before.NewRegion("old").Sources("x").Add()
+
+	after, err := video.NewVideoLayout(video.VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	after.NewRegion("grid").Position(16, 0).Size(320, 240).Reuse(video.ReusePolicyShowNewest).Sources("*").Add()
+	after.NewRegion("new").Sources("y").Add()
+
+	disabled := false
+	d := DiffHooksParams(
+		&HooksParams{FriendlyName: "a", VideoLayout: before, AudioSources: []string{"teacher", "*Team"}},
+		&HooksParams{FriendlyName: "a", VideoLayout: after, AudioSources: []string{"*Team", "student*"}, Enabled: &disabled, Format: MP4},
+	)
+
+	want := `enabled: true -> false
+format: "webm" -> "mp4"
+audio_sources: + "student*"
+audio_sources: - "teacher"
+video_layout:
+  region "new" added
+  region "old" removed
+  region "grid" changed:
+    x_pos: 0 -> 16
+    width: 640 -> 320
+    height: 480 -> 240
+    reuse: "show_oldest" -> "show_newest"
+`
+	if got := d.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"video_layout":{"added":["new"],"removed":["old"],"changed":[{"region":"grid","changes":[{"property":"x_pos","before":0,"after":16}`) {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
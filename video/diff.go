@@ -0,0 +1,239 @@
+package video
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a property with different values on both sides of a diff.
+// A nil value means the property is unset and has no default, such as max_columns.
+type Change struct {
+	Property string      `json:"property"`
+	Before   interface{} `json:"before"`
+	After    interface{} `json:"after"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Property, formatValue(c.Before), formatValue(c.After))
+}
+
+// RegionDiff lists the changed properties of a region on both layouts.
+type RegionDiff struct {
+	Region  string   `json:"region"`
+	Changes []Change `json:"changes"`
+}
+
+// LayoutDiff is the semantic difference between two video layouts.
+// It is encoded as JSON for code review tools and as text by String.
+type LayoutDiff struct {
+	Added   []string     `json:"added,omitempty"`
+	Removed []string     `json:"removed,omitempty"`
+	Changed []RegionDiff `json:"changed,omitempty"`
+}
+
+// DiffLayouts compares the regions of both layouts by name.
+// Properties are compared with Twilio's defaults filled in, so a missing
+// x_pos equals 0 and a missing reuse equals show_oldest, and lists that work as sets,
+// cells_excluded and video_sources_excluded, are compared without order.
+// A nil layout has no regions.
+func DiffLayouts(before, after *VideoLayout) *LayoutDiff {
+	d := &LayoutDiff{}
+	beforeRegs, afterRegs := regionsByName(before), regionsByName(after)
+
+	for _, r := range regionsOf(after) {
+		if _, ok := beforeRegs[r.Name]; !ok {
+			d.Added = append(d.Added, r.Name)
+		}
+	}
+	for _, r := range regionsOf(before) {
+		other, ok := afterRegs[r.Name]
+		if !ok {
+			d.Removed = append(d.Removed, r.Name)
+			continue
+		}
+
+		bp := normalizeProp(r, resolutionOf(before))
+		ap := normalizeProp(other, resolutionOf(after))
+		var changes []Change
+		for _, key := range bp.keys(ap) {
+			if !reflect.DeepEqual(bp[key], ap[key]) {
+				changes = append(changes, Change{Property: key, Before: bp[key], After: ap[key]})
+			}
+		}
+		if len(changes) > 0 {
+			d.Changed = append(d.Changed, RegionDiff{Region: r.Name, Changes: changes})
+		}
+	}
+	return d
+}
+
+// Empty reports whether both layouts are equivalent.
+func (d *LayoutDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d *LayoutDiff) String() string {
+	var b bytes.Buffer
+	for _, name := range d.Added {
+		fmt.Fprintf(&b, "region %q added\n", name)
+	}
+	for _, name := range d.Removed {
+		fmt.Fprintf(&b, "region %q removed\n", name)
+	}
+	for _, rd := range d.Changed {
+		fmt.Fprintf(&b, "region %q changed:\n", rd.Region)
+		for _, c := range rd.Changes {
+			fmt.Fprintf(&b, "  %s\n", c)
+		}
+	}
+	return b.String()
+}
+
+func regionsOf(l *VideoLayout) []*Region {
+	if l == nil {
+		return nil
+	}
+	return l.regions
+}
+
+func resolutionOf(l *VideoLayout) Resolution {
+	if l == nil {
+		return Resolution{}
+	}
+	return l.Resolution
+}
+
+func regionsByName(l *VideoLayout) map[string]*Region {
+	m := map[string]*Region{}
+	for _, r := range regionsOf(l) {
+		if r != nil {
+			m[r.Name] = r
+		}
+	}
+	return m
+}
+
+// normalizedProp is a region's properties keyed by their JSON names, with defaults filled in.
+type normalizedProp map[string]interface{}
+
+// keys returns the properties set on either side, in the order of RegionProp's fields
+// followed by unknown properties sorted by name.
+func (p normalizedProp) keys(other normalizedProp) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, k := range regionPropKeys {
+		keys = append(keys, k)
+		seen[k] = true
+	}
+	var extra []string
+	for _, m := range []normalizedProp{p, other} {
+		for k := range m {
+			if !seen[k] {
+				extra = append(extra, k)
+				seen[k] = true
+			}
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+func normalizeProp(r *Region, res Resolution) normalizedProp {
+	p := r.Prop
+	if p == nil {
+		p = &RegionProp{}
+	}
+
+	n := normalizedProp{
+		"x_pos":                  0,
+		"y_pos":                  0,
+		"z_pos":                  0,
+		"width":                  nil,
+		"height":                 nil,
+		"max_columns":            nil,
+		"max_rows":               nil,
+		"cells_excluded":         sortedCells(p.CellsExcluded),
+		"reuse":                  ReuseShowOldest,
+		"video_sources":          stringList(p.VideoSources),
+		"video_sources_excluded": sortedStrings(p.VideoSourcesExcluded),
+	}
+	if p.XPos != nil {
+		n["x_pos"] = int(*p.XPos)
+	}
+	if p.YPos != nil {
+		n["y_pos"] = int(*p.YPos)
+	}
+	if p.ZPos != nil {
+		n["z_pos"] = int(*p.ZPos)
+	}
+	// A region without width or height reaches the composition's edge,
+	// which is only known with the resolution.
+	if !res.IsZero() {
+		bounds := r.Bounds(res)
+		n["width"], n["height"] = bounds.Width, bounds.Height
+	}
+	if p.Width != nil {
+		n["width"] = int(*p.Width)
+	}
+	if p.Height != nil {
+		n["height"] = int(*p.Height)
+	}
+	if p.MaxColumns != nil {
+		n["max_columns"] = int(*p.MaxColumns)
+	}
+	if p.MaxRows != nil {
+		n["max_rows"] = int(*p.MaxRows)
+	}
+	if p.Reuse != nil {
+		n["reuse"] = *p.Reuse
+	}
+	for k, raw := range p.extra {
+		// Decoded, so the key order and spacing of nested objects do not count.
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			v = string(raw)
+		}
+		n[k] = v
+	}
+	return n
+}
+
+func sortedCells(cells []uint32) []int {
+	out := []int{}
+	for _, c := range cells {
+		out = append(out, int(c))
+	}
+	sort.Ints(out)
+	return out
+}
+
+func stringList(s []string) []string {
+	return append([]string{}, s...)
+}
+
+func sortedStrings(s []string) []string {
+	out := stringList(s)
+	sort.Strings(out)
+	return out
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "(unset)"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
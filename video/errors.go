@@ -0,0 +1,54 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegionError is a violation found on a single region of a video layout.
+type RegionError struct {
+	Region string
+	Err    error
+}
+
+func (e *RegionError) Error() string {
+	return fmt.Sprintf("region %q: %v", e.Region, e.Err)
+}
+
+func (e *RegionError) Unwrap() error {
+	return e.Err
+}
+
+// RuleError is a violation found on a single rule of a list, such as recording or subscribe rules.
+type RuleError struct {
+	Index int
+	Err   error
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("rule %d: %v", e.Index, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors lists every violation found on a video layout at once.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// errOrNil returns the errors as an error, or nil when there are none.
+// This avoids handing out a non-nil error interface holding an empty list.
+func (e ValidationErrors) errOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,379 @@
+// Package ffmpeg builds the ffmpeg command that mixes the raw recordings of a room
+// like a composition would, for rooms that are mixed locally instead.
+// The command is only generated, running it and fetching the recordings is left to the caller.
+//
+// Tracks are aligned with the timeline package, and video sources are placed
+// in the layout's regions with the placement package, so the result follows
+// the same rules as the composition preview. Trim is not applied.
+package ffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/placement"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+	"github.com/matthxwpavin/twilio-compositions/video/timeline"
+)
+
+// DefaultFrameRate is the frame rate of the mixed video when none is given.
+const DefaultFrameRate = 24
+
+type Options struct {
+	// The file the mix is written to.
+	Output string
+
+	// InputPath returns where the recording's media is stored,
+	// by default its SID with its container as extension, such as RT123.mka.
+	InputPath func(rec *timeline.Track) string
+
+	// The frame rate of the mixed video, DefaultFrameRate when zero.
+	FrameRate int
+
+	// The color behind the regions, black when empty.
+	Background string
+}
+
+// Command is an ffmpeg command line.
+type Command struct {
+	// The arguments, starting with the ffmpeg executable.
+	Args []string
+}
+
+// String returns the command quoted for a POSIX shell.
+func (c *Command) String() string {
+	quoted := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Generate returns the command mixing the room's recordings as the params describe.
+// The video layout, the resolution and the audio sources of the params are used,
+// its format picks the codecs: H.264 and AAC for mp4, VP8 and Opus for webm.
+// Without a video layout only the audio is mixed.
+func Generate(
+	recs []recording.RecordingInstance,
+	roomStart time.Time,
+	params *composition.ComposeParams,
+	opts Options,
+) (*Command, error) {
+	if params == nil {
+		return nil, errors.New("Error, compose params must not be nil.")
+	}
+	if opts.Output == "" {
+		return nil, errors.New("Error, output must not be empty.")
+	}
+	if opts.InputPath == nil {
+		opts.InputPath = defaultInputPath
+	}
+	if opts.FrameRate == 0 {
+		opts.FrameRate = DefaultFrameRate
+	}
+	if opts.Background == "" {
+		opts.Background = "black"
+	}
+	format := composition.MediaFormatOf(params.Format)
+	if format != composition.MediaFormatMP4 && format != composition.MediaFormatWebM {
+		return nil, fmt.Errorf("Error, unsupported format %q.", format)
+	}
+
+	alignment := timeline.Align(params.RoomSid, roomStart, recs)
+	var audios, videos []*timeline.Track
+	for _, p := range alignment.Participants {
+		for _, pair := range p.Pairs {
+			if pair.Audio != nil {
+				audios = append(audios, pair.Audio)
+			}
+			if pair.Video != nil {
+				videos = append(videos, pair.Video)
+			}
+		}
+	}
+	audios = includedAudios(params, audios)
+
+	g := &graph{}
+	var segments []segment
+	var res video.Resolution
+	if params.VideoLayout != nil {
+		// The resolution parameter wins over the layout's, like in a composition.
+		layout := *params.VideoLayout
+		if params.Resolution != nil {
+			layout.Resolution = *params.Resolution
+		}
+		if layout.Resolution.IsZero() {
+			layout.Resolution = video.DefaultResolution()
+		}
+		res = layout.Resolution
+
+		var err error
+		if segments, err = placeVideos(&layout, alignment, videos); err != nil {
+			return nil, err
+		}
+	}
+	if params.VideoLayout == nil && len(audios) == 0 {
+		return nil, errors.New("Error, there is nothing to mix, no video layout and no audio track is included.")
+	}
+
+	args := []string{"ffmpeg", "-y"}
+	for _, a := range audios {
+		g.input(a)
+	}
+	for _, s := range segments {
+		g.input(s.track)
+	}
+	for _, t := range g.inputs {
+		args = append(args, "-i", opts.InputPath(t))
+	}
+
+	duration := seconds(alignment.DurationMs)
+	var maps []string
+	if params.VideoLayout != nil {
+		g.videoFilters(segments, res, opts, duration)
+		maps = append(maps, "-map", "[vout]")
+	}
+	if len(audios) > 0 {
+		g.audioFilters(audios)
+		maps = append(maps, "-map", "[aout]")
+	}
+
+	args = append(args, "-filter_complex", strings.Join(g.filters, ";"))
+	args = append(args, maps...)
+	if params.VideoLayout != nil {
+		if format == composition.MediaFormatMP4 {
+			args = append(args, "-c:v", "libx264", "-pix_fmt", "yuv420p")
+		} else {
+			args = append(args, "-c:v", "libvpx", "-b:v", "1M")
+		}
+	}
+	if len(audios) > 0 {
+		if format == composition.MediaFormatMP4 {
+			args = append(args, "-c:a", "aac")
+		} else {
+			args = append(args, "-c:a", "libopus")
+		}
+	}
+	args = append(args, "-t", duration, "-f", string(format), opts.Output)
+	return &Command{Args: args}, nil
+}
+
+func defaultInputPath(t *timeline.Track) string {
+	ext := string(t.Container)
+	if ext == "" {
+		ext = "mka"
+		if t.Type == recording.TypeVideo {
+			ext = "mkv"
+		}
+	}
+	return t.RecordingSid + "." + ext
+}
+
+func includedAudios(params *composition.ComposeParams, audios []*timeline.Track) []*timeline.Track {
+	names := make([]string, len(audios))
+	for i, a := range audios {
+		names[i] = a.TrackName
+	}
+	included := map[string]bool{}
+	for _, name := range composition.IncludedAudioTracks(params, names) {
+		included[name] = true
+	}
+
+	var ret []*timeline.Track
+	for _, a := range audios {
+		if included[a.TrackName] {
+			ret = append(ret, a)
+		}
+	}
+	return ret
+}
+
+// segment is a video track shown in a cell for a period, drawn with the region's z_pos.
+type segment struct {
+	track      *timeline.Track
+	bounds     video.Rect
+	start, end time.Duration
+	z          int16
+	order      int
+}
+
+// placeVideos simulates the placement of the videos and merges the consecutive slices
+// during which a track stays in the same cell.
+func placeVideos(l *video.VideoLayout, alignment *timeline.Alignment, videos []*timeline.Track) ([]segment, error) {
+	bySource := map[string]*timeline.Track{}
+	var tracks []placement.Track
+	for _, v := range videos {
+		bySource[v.SourceSid] = v
+		tracks = append(tracks, placement.Track{
+			Name:           v.TrackName,
+			Sid:            v.SourceSid,
+			ParticipantSid: participantOf(alignment, v),
+			Start:          v.Start(),
+			End:            v.End(),
+		})
+	}
+	slices, err := placement.Simulate(l, tracks)
+	if err != nil {
+		return nil, err
+	}
+
+	regionOrder := map[string]int{}
+	regionZ := map[string]int16{}
+	for i, r := range l.GetRegions() {
+		regionOrder[r.Name] = i
+		regionZ[r.Name] = r.Z()
+	}
+
+	var segments []segment
+	open := map[string]int{}
+	for _, s := range slices {
+		next := map[string]int{}
+		for _, p := range s.Placements {
+			key := fmt.Sprintf("%s/%s/%d", p.Track.Sid, p.Region, p.Cell)
+			if i, ok := open[key]; ok && segments[i].bounds == p.Bounds && segments[i].end == s.Start {
+				segments[i].end = s.End
+				next[key] = i
+				continue
+			}
+			next[key] = len(segments)
+			segments = append(segments, segment{
+				track:  bySource[p.Track.Sid],
+				bounds: p.Bounds,
+				start:  s.Start,
+				end:    s.End,
+				z:      regionZ[p.Region],
+				order:  regionOrder[p.Region]*1000000 + p.Cell,
+			})
+		}
+		open = next
+	}
+
+	sort.SliceStable(segments, func(i, j int) bool {
+		if segments[i].z != segments[j].z {
+			return segments[i].z < segments[j].z
+		}
+		if segments[i].order != segments[j].order {
+			return segments[i].order < segments[j].order
+		}
+		return segments[i].start < segments[j].start
+	})
+	return segments, nil
+}
+
+func participantOf(alignment *timeline.Alignment, t *timeline.Track) string {
+	for _, p := range alignment.Participants {
+		for _, pair := range p.Pairs {
+			if pair.Audio == t || pair.Video == t {
+				return p.ParticipantSid
+			}
+		}
+	}
+	return ""
+}
+
+// graph collects the inputs and the filters of the filter_complex.
+type graph struct {
+	inputs  []*timeline.Track
+	filters []string
+}
+
+func (g *graph) input(t *timeline.Track) int {
+	for i, in := range g.inputs {
+		if in == t {
+			return i
+		}
+	}
+	g.inputs = append(g.inputs, t)
+	return len(g.inputs) - 1
+}
+
+func (g *graph) add(format string, args ...interface{}) {
+	g.filters = append(g.filters, fmt.Sprintf(format, args...))
+}
+
+// videoFilters delays every video input to its start in the room, splits the ones shown
+// in several segments, fits each copy in its cell keeping its aspect ratio,
+// and overlays them on the background in z_pos order while their segment lasts.
+func (g *graph) videoFilters(segments []segment, res video.Resolution, opts Options, duration string) {
+	g.add("color=c=%s:s=%s:r=%d:d=%s[base]", opts.Background, res, opts.FrameRate, duration)
+
+	uses := map[int][]int{}
+	var inputs []int
+	for i, s := range segments {
+		in := g.input(s.track)
+		if _, ok := uses[in]; !ok {
+			inputs = append(inputs, in)
+		}
+		uses[in] = append(uses[in], i)
+	}
+
+	labels := make([]string, len(segments))
+	for _, in := range inputs {
+		g.add("[%d:v]setpts=PTS-STARTPTS+%s/TB,fps=%d[v%d]", in, seconds(g.inputs[in].StartMs), opts.FrameRate, in)
+		segs := uses[in]
+		if len(segs) == 1 {
+			labels[segs[0]] = fmt.Sprintf("v%d", in)
+			continue
+		}
+		var outs string
+		for k, seg := range segs {
+			labels[seg] = fmt.Sprintf("v%d_%d", in, k)
+			outs += "[" + labels[seg] + "]"
+		}
+		g.add("[v%d]split=%d%s", in, len(segs), outs)
+	}
+
+	last := "base"
+	for i, s := range segments {
+		b := s.bounds
+		g.add("[%s]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=%s[s%d]",
+			labels[i], b.Width, b.Height, b.Width, b.Height, opts.Background, i)
+		out := fmt.Sprintf("o%d", i)
+		if i == len(segments)-1 {
+			out = "vout"
+		}
+		g.add("[%s][s%d]overlay=x=%d:y=%d:eof_action=pass:enable='between(t,%s,%s)'[%s]",
+			last, i, b.X, b.Y, seconds(s.start.Milliseconds()), seconds(s.end.Milliseconds()), out)
+		last = out
+	}
+	if len(segments) == 0 {
+		g.add("[base]null[vout]")
+	}
+}
+
+// audioFilters delays every audio input to its start in the room and mixes them.
+func (g *graph) audioFilters(audios []*timeline.Track) {
+	var labels string
+	for _, a := range audios {
+		in := g.input(a)
+		g.add("[%d:a]adelay=delays=%d:all=1[a%d]", in, a.StartMs, in)
+		labels += fmt.Sprintf("[a%d]", in)
+	}
+	if len(audios) == 1 {
+		g.add("%sanull[aout]", labels)
+		return
+	}
+	g.add("%samix=inputs=%d:duration=longest:dropout_transition=0[aout]", labels, len(audios))
+}
+
+// seconds formats milliseconds as seconds for ffmpeg.
+func seconds(ms int64) string {
+	return fmt.Sprintf("%d.%03d", ms/1000, ms%1000)
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_./:=+,@%", c)) {
+			return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+		}
+	}
+	return s
+}
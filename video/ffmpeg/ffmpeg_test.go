@@ -0,0 +1,134 @@
+package ffmpeg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+	"github.com/matthxwpavin/twilio-compositions/video/timeline"
+)
+
+var roomStart = time.Date(2021, 5, 18, 10, 0, 0, 0, time.UTC)
+
+// rec places the recording startMs after the room's first media on Twilio's clock,
+// while its creation time is only precise to the second.
+func rec(sid, participant, name string, typ recording.Type, startMs, durationSec int) recording.RecordingInstance {
+	r := recording.RecordingInstance{
+		Sid:         sid,
+		Status:      recording.StatusCompleted,
+		DateCreated: roomStart.Add(time.Duration(startMs/1000) * time.Second),
+		Offset:      7000000 + startMs,
+		SourceSid:   "MT" + sid,
+		TrackName:   name,
+		Type:        typ,
+		Duration:    durationSec,
+	}
+	if typ == recording.TypeAudio {
+		r.ContainerFormat = recording.ContainerMka
+	} else {
+		r.ContainerFormat = recording.ContainerMkv
+	}
+	r.GroupingSids.RoomSid = "RM1"
+	r.GroupingSids.ParticipantSid = participant
+	return r
+}
+
+func roomRecordings() []recording.RecordingInstance {
+	return []recording.RecordingInstance{
+		rec("RT1", "PA1", "alice-mic", recording.TypeAudio, 0, 10),
+		rec("RT2", "PA1", "alice-cam", recording.TypeVideo, 0, 10),
+		rec("RT3", "PA2", "bob-mic", recording.TypeAudio, 2350, 8),
+		rec("RT4", "PA2", "bob-cam", recording.TypeVideo, 2400, 8),
+		rec("RT5", "PA1", "screen", recording.TypeVideo, 5125, 3),
+	}
+}
+
+func testLayout(t *testing.T) *video.VideoLayout {
+	l, err := video.NewVideoLayout(video.VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.NewRegion("main").Sources("*-cam").Add(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.NewRegion("pip").Position(480, 360).Size(160, 120).Z(1).Sources("screen").Add(); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestGenerate(t *testing.T) {
+	cmd, err := Generate(roomRecordings(), roomStart, &composition.ComposeParams{
+		RoomSid:      "RM1",
+		VideoLayout:  testLayout(t),
+		AudioSources: []string{"*"},
+		Format:       composition.MP4,
+	}, Options{Output: "out.mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"ffmpeg -y -i RT1.mka -i RT3.mka -i RT2.mkv -i RT4.mkv -i RT5.mkv -filter_complex '",
+		"color=c=black:s=640x480:r=24:d=10.400[base];",
+		"[2:v]setpts=PTS-STARTPTS+0.000/TB,fps=24[v2];",
+		"[v2]split=2[v2_0][v2_1];",
+		"[3:v]setpts=PTS-STARTPTS+2.400/TB,fps=24[v3];",
+		"[4:v]setpts=PTS-STARTPTS+5.125/TB,fps=24[v4];",
+		"[v2_0]scale=640:480:force_original_aspect_ratio=decrease,pad=640:480:(ow-iw)/2:(oh-ih)/2:color=black[s0];",
+		"[base][s0]overlay=x=0:y=0:eof_action=pass:enable='\\''between(t,0.000,2.400)'\\''[o0];",
+		"[v2_1]scale=320:480:force_original_aspect_ratio=decrease,pad=320:480:(ow-iw)/2:(oh-ih)/2:color=black[s1];",
+		"[o0][s1]overlay=x=0:y=0:eof_action=pass:enable='\\''between(t,2.400,10.000)'\\''[o1];",
+		"[v3]scale=320:480:force_original_aspect_ratio=decrease,pad=320:480:(ow-iw)/2:(oh-ih)/2:color=black[s2];",
+		"[o1][s2]overlay=x=320:y=0:eof_action=pass:enable='\\''between(t,2.400,10.400)'\\''[o2];",
+		"[v4]scale=160:120:force_original_aspect_ratio=decrease,pad=160:120:(ow-iw)/2:(oh-ih)/2:color=black[s3];",
+		"[o2][s3]overlay=x=480:y=360:eof_action=pass:enable='\\''between(t,5.125,8.125)'\\''[vout];",
+		"[0:a]adelay=delays=0:all=1[a0];",
+		"[1:a]adelay=delays=2350:all=1[a1];",
+		"[a0][a1]amix=inputs=2:duration=longest:dropout_transition=0[aout]",
+		"' -map '[vout]' -map '[aout]' -c:v libx264 -pix_fmt yuv420p -c:a aac -t 10.400 -f mp4 out.mp4",
+	}, "")
+	if got := cmd.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateAudioOnly(t *testing.T) {
+	cmd, err := Generate(roomRecordings(), roomStart, &composition.ComposeParams{
+		RoomSid:              "RM1",
+		AudioSources:         []string{"*-mic"},
+		AudioSourcesExcluded: []string{"alice*"},
+	}, Options{
+		Output: "out dir/bob.webm",
+		InputPath: func(t *timeline.Track) string {
+			return "/recordings/" + t.TrackName + "." + string(t.Container)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "ffmpeg -y -i /recordings/bob-mic.mka -filter_complex " +
+		"'[0:a]adelay=delays=2350:all=1[a0];[a0]anull[aout]' " +
+		"-map '[aout]' -c:a libopus -t 10.400 -f webm 'out dir/bob.webm'"
+	if got := cmd.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	if _, err := Generate(roomRecordings(), roomStart, nil, Options{Output: "out.mp4"}); err == nil {
+		t.Error("generated without params")
+	}
+	if _, err := Generate(roomRecordings(), roomStart, &composition.ComposeParams{}, Options{}); err == nil {
+		t.Error("generated without output")
+	}
+	if _, err := Generate(roomRecordings(), roomStart, &composition.ComposeParams{
+		AudioSources: []string{"nobody"},
+	}, Options{Output: "out.mp4"}); err == nil {
+		t.Error("generated without anything to mix")
+	}
+}
@@ -0,0 +1,62 @@
+package video
+
+// Rect is an area of the composition in pixels.
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// Bounds returns the area the region covers on a composition of the given resolution.
+// Twilio stretches a region without width or height to the composition's edge,
+// so a region without properties covers the whole composition.
+func (r *Region) Bounds(res Resolution) Rect {
+	rect := Rect{Width: int(res.Width), Height: int(res.Height)}
+	if r.Prop == nil {
+		return rect
+	}
+
+	p := r.Prop
+	if p.XPos != nil {
+		rect.X = int(*p.XPos)
+	}
+	if p.YPos != nil {
+		rect.Y = int(*p.YPos)
+	}
+	rect.Width -= rect.X
+	rect.Height -= rect.Y
+	if p.Width != nil {
+		rect.Width = int(*p.Width)
+	}
+	if p.Height != nil {
+		rect.Height = int(*p.Height)
+	}
+	return rect
+}
+
+// Z returns the region's z_pos, 0 when it is not set.
+func (r *Region) Z() int16 {
+	if r.Prop == nil || r.Prop.ZPos == nil {
+		return 0
+	}
+	return *r.Prop.ZPos
+}
+
+// Cells splits the area into a grid of equally sized cells,
+// indexed from left to right and from top to bottom like Twilio's placement grid.
+// The last column and row take the pixels left over by the division.
+func (r Rect) Cells(columns, rows int) []Rect {
+	if columns < 1 || rows < 1 {
+		return nil
+	}
+
+	cells := make([]Rect, 0, columns*rows)
+	for row := 0; row < rows; row++ {
+		y0 := r.Y + row*r.Height/rows
+		y1 := r.Y + (row+1)*r.Height/rows
+		for col := 0; col < columns; col++ {
+			x0 := r.X + col*r.Width/columns
+			x1 := r.X + (col+1)*r.Width/columns
+			cells = append(cells, Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0})
+		}
+	}
+	return cells
+}
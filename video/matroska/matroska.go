@@ -0,0 +1,560 @@
+// Package matroska reads the headers of Matroska files, the mka and mkv containers
+// of Twilio's recordings, to check downloaded files against their metadata.
+//
+// Only the elements needed for that are decoded: the EBML header, the segment info,
+// the tracks and the timestamps of the blocks. The media itself is skipped.
+// Segments and clusters of unknown size, which live recorders write, are supported.
+package matroska
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+// Element IDs, with their marker bits, see https://www.matroska.org/technical/elements.html
+const (
+	idEBML           = 0x1A45DFA3
+	idDocType        = 0x4282
+	idSegment        = 0x18538067
+	idInfo           = 0x1549A966
+	idTimestampScale = 0x2AD7B1
+	idDuration       = 0x4489
+	idDateUTC        = 0x4461
+	idTracks         = 0x1654AE6B
+	idTrackEntry     = 0xAE
+	idTrackNumber    = 0xD7
+	idTrackType      = 0x83
+	idCodecID        = 0x86
+	idVideo          = 0xE0
+	idPixelWidth     = 0xB0
+	idPixelHeight    = 0xBA
+	idAudio          = 0xE1
+	idSamplingFreq   = 0xB5
+	idChannels       = 0x9F
+	idCluster        = 0x1F43B675
+	idTimestamp      = 0xE7
+	idSimpleBlock    = 0xA3
+	idBlockGroup     = 0xA0
+	idBlock          = 0xA1
+)
+
+// clusterChildren are the elements a cluster of unknown size can hold,
+// any other element ends it.
+var clusterChildren = map[uint32]bool{
+	idTimestamp:   true,
+	0x5854:        true, // SilentTracks
+	0xA7:          true, // Position
+	0xAB:          true, // PrevSize
+	idSimpleBlock: true,
+	idBlockGroup:  true,
+	0xAF:          true, // EncryptedBlock
+}
+
+// maxValueSize bounds the values read in memory, larger elements are media and are skipped.
+const maxValueSize = 1 << 20
+
+// DefaultTimestampScale is the nanoseconds per timestamp unit when the file does not say.
+const DefaultTimestampScale = 1000000
+
+type TrackType uint64
+
+const (
+	TrackTypeVideo TrackType = 1
+	TrackTypeAudio TrackType = 2
+)
+
+// Track is a track entry of the file.
+type Track struct {
+	Number  uint64
+	Type    TrackType
+	CodecID string
+
+	// Set for video tracks.
+	Width, Height uint64
+
+	// Set for audio tracks.
+	SamplingFrequency float64
+	Channels          uint64
+}
+
+// Codec returns the Twilio codec of the track, or an empty codec when the codec ID is unknown.
+func (t Track) Codec() recording.Codec {
+	switch t.CodecID {
+	case "A_OPUS":
+		return recording.CodecOPUS
+	case "V_VP8":
+		return recording.CodecVP8
+	case "V_MPEG4/ISO/AVC":
+		return recording.CodecH264
+	}
+	return ""
+}
+
+// Info is what the header and the blocks of a file tell.
+type Info struct {
+	// DocType is matroska or webm.
+	DocType string
+
+	TimestampScale uint64
+
+	// Duration from the segment info, zero when it is not written.
+	Duration time.Duration
+
+	// DateUTC is when the file was muxed, zero when it is not written.
+	DateUTC time.Time
+
+	Tracks []Track
+
+	// The timestamps of the first and the last block, and how many blocks there are.
+	FirstTimestamp time.Duration
+	LastTimestamp  time.Duration
+	Blocks         int
+
+	// Size is the number of bytes read.
+	Size int64
+
+	// Truncated is set when the file ends inside an element,
+	// TruncatedAt tells where.
+	Truncated   bool
+	TruncatedAt int64
+}
+
+// MediaDuration returns the duration from the segment info,
+// or the time between the first and the last block when it is not written.
+func (i *Info) MediaDuration() time.Duration {
+	if i.Duration > 0 {
+		return i.Duration
+	}
+	return i.LastTimestamp - i.FirstTimestamp
+}
+
+// Container returns mkv when the file has a video track, mka otherwise.
+func (i *Info) Container() recording.Container {
+	for _, t := range i.Tracks {
+		if t.Type == TrackTypeVideo {
+			return recording.ContainerMkv
+		}
+	}
+	return recording.ContainerMka
+}
+
+// ParseFile parses the file at path.
+func ParseFile(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads the file from r up to its end. A file cut short is not an error,
+// it is reported by Info.Truncated. A file that is not Matroska is an error.
+func Parse(r io.Reader) (*Info, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	info := &Info{TimestampScale: DefaultTimestampScale}
+
+	err := d.parse(info)
+	info.Size = d.pos
+	if err == io.ErrUnexpectedEOF {
+		info.Truncated = true
+		info.TruncatedAt = d.pos
+		return info, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+type header struct {
+	id    uint32
+	size  int64 // -1 when unknown.
+	start int64 // Offset of the data.
+}
+
+func (h header) end() int64 {
+	return h.start + h.size
+}
+
+type decoder struct {
+	r       *bufio.Reader
+	pos     int64
+	pending *header
+}
+
+func (d *decoder) parse(info *Info) error {
+	h, err := d.next()
+	if err == io.EOF {
+		return errors.New("Error, the file is empty.")
+	}
+	if err != nil {
+		return err
+	}
+	if h.id != idEBML {
+		return fmt.Errorf("Error, not a Matroska file, it starts with element %X.", h.id)
+	}
+	if err := d.children(h, func(c header) error {
+		if c.id == idDocType {
+			v, err := d.str(c)
+			info.DocType = v
+			return err
+		}
+		return d.skip(c)
+	}); err != nil {
+		return err
+	}
+	if info.DocType != "matroska" && info.DocType != "webm" {
+		return fmt.Errorf("Error, unsupported document type %q.", info.DocType)
+	}
+
+	seg, err := d.next()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if seg.id != idSegment {
+		return fmt.Errorf("Error, expected a segment, got element %X.", seg.id)
+	}
+
+	var durationUnits float64
+	for seg.size < 0 || d.pos < seg.end() {
+		h, err := d.next()
+		if err == io.EOF {
+			if seg.size < 0 {
+				break
+			}
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		switch h.id {
+		case idInfo:
+			err = d.children(h, func(c header) error {
+				var err error
+				switch c.id {
+				case idTimestampScale:
+					info.TimestampScale, err = d.uint(c)
+				case idDuration:
+					durationUnits, err = d.float(c)
+				case idDateUTC:
+					var ns uint64
+					ns, err = d.uint(c)
+					info.DateUTC = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(int64(ns)))
+				default:
+					err = d.skip(c)
+				}
+				return err
+			})
+		case idTracks:
+			err = d.children(h, func(c header) error {
+				if c.id != idTrackEntry {
+					return d.skip(c)
+				}
+				track, err := d.track(c)
+				info.Tracks = append(info.Tracks, track)
+				return err
+			})
+		case idCluster:
+			err = d.cluster(h, info)
+		default:
+			err = d.skip(h)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	info.Duration = time.Duration(durationUnits * float64(info.TimestampScale))
+	return nil
+}
+
+func (d *decoder) track(h header) (Track, error) {
+	var t Track
+	err := d.children(h, func(c header) error {
+		var err error
+		switch c.id {
+		case idTrackNumber:
+			t.Number, err = d.uint(c)
+		case idTrackType:
+			var v uint64
+			v, err = d.uint(c)
+			t.Type = TrackType(v)
+		case idCodecID:
+			t.CodecID, err = d.str(c)
+		case idVideo:
+			err = d.children(c, func(v header) error {
+				var err error
+				switch v.id {
+				case idPixelWidth:
+					t.Width, err = d.uint(v)
+				case idPixelHeight:
+					t.Height, err = d.uint(v)
+				default:
+					err = d.skip(v)
+				}
+				return err
+			})
+		case idAudio:
+			err = d.children(c, func(a header) error {
+				var err error
+				switch a.id {
+				case idSamplingFreq:
+					t.SamplingFrequency, err = d.float(a)
+				case idChannels:
+					t.Channels, err = d.uint(a)
+				default:
+					err = d.skip(a)
+				}
+				return err
+			})
+		default:
+			err = d.skip(c)
+		}
+		return err
+	})
+	return t, err
+}
+
+func (d *decoder) cluster(h header, info *Info) error {
+	var clusterTimestamp uint64
+	for h.size < 0 || d.pos < h.end() {
+		c, err := d.next()
+		if err == io.EOF {
+			if h.size < 0 {
+				return nil
+			}
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if h.size < 0 && !clusterChildren[c.id] {
+			d.pending = &c
+			return nil
+		}
+
+		switch c.id {
+		case idTimestamp:
+			clusterTimestamp, err = d.uint(c)
+		case idSimpleBlock:
+			err = d.block(c, clusterTimestamp, info)
+		case idBlockGroup:
+			err = d.children(c, func(b header) error {
+				if b.id == idBlock {
+					return d.block(b, clusterTimestamp, info)
+				}
+				return d.skip(b)
+			})
+		default:
+			err = d.skip(c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// block reads the timestamp of a block, made of the track number, a signed 16 bits
+// timestamp relative to the cluster's and the flags, then skips its frames.
+func (d *decoder) block(h header, clusterTimestamp uint64, info *Info) error {
+	if h.size < 0 {
+		return errors.New("Error, a block must have a known size.")
+	}
+	_, n, err := d.vint(false)
+	if err != nil {
+		return err
+	}
+	if int64(n)+2 > h.size {
+		return errors.New("Error, the block is too small.")
+	}
+	var rel [2]byte
+	if _, err := io.ReadFull(d.r, rel[:]); err != nil {
+		return unexpected(err)
+	}
+	d.pos += 2
+	if err := d.discard(h.end() - d.pos); err != nil {
+		return err
+	}
+
+	units := int64(clusterTimestamp) + int64(int16(binary.BigEndian.Uint16(rel[:])))
+	ts := time.Duration(units * int64(info.TimestampScale))
+	if info.Blocks == 0 || ts < info.FirstTimestamp {
+		info.FirstTimestamp = ts
+	}
+	if info.Blocks == 0 || ts > info.LastTimestamp {
+		info.LastTimestamp = ts
+	}
+	info.Blocks++
+	return nil
+}
+
+// next reads the header of the next element. It returns io.EOF only at the end of the file
+// before the element starts.
+func (d *decoder) next() (header, error) {
+	if d.pending != nil {
+		h := *d.pending
+		d.pending = nil
+		return h, nil
+	}
+
+	id, n, err := d.vint(true)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF && n == 0 {
+			return header{}, io.EOF
+		}
+		return header{}, err
+	}
+	size, _, err := d.vint(false)
+	if err != nil {
+		return header{}, err
+	}
+	h := header{id: uint32(id), size: int64(size), start: d.pos}
+	if size == math.MaxUint64 {
+		h.size = -1
+	}
+	return h, nil
+}
+
+// vint reads a variable length integer, keeping its length marker for IDs.
+// The number of bytes read is returned along with io.ErrUnexpectedEOF.
+func (d *decoder) vint(keepMarker bool) (uint64, int, error) {
+	first, err := d.r.ReadByte()
+	if err != nil {
+		return 0, 0, unexpected(err)
+	}
+	d.pos++
+	length := 1
+	for mask := byte(0x80); first&mask == 0; mask >>= 1 {
+		length++
+		if mask == 1 {
+			return 0, 1, fmt.Errorf("Error, invalid variable length integer at %d.", d.pos-1)
+		}
+	}
+	if keepMarker && length > 4 {
+		return 0, 1, fmt.Errorf("Error, invalid element ID at %d.", d.pos-1)
+	}
+
+	v := uint64(first)
+	if !keepMarker {
+		v &= uint64(0xFF >> uint(length))
+	}
+	for i := 1; i < length; i++ {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return 0, i, unexpected(err)
+		}
+		d.pos++
+		v = v<<8 | uint64(b)
+	}
+	if !keepMarker && v == (uint64(1)<<(7*uint(length)))-1 {
+		return math.MaxUint64, length, nil
+	}
+	return v, length, nil
+}
+
+// children calls fn for every child of an element of known size. fn must consume the child.
+func (d *decoder) children(h header, fn func(header) error) error {
+	if h.size < 0 {
+		return fmt.Errorf("Error, element %X must have a known size.", h.id)
+	}
+	for d.pos < h.end() {
+		c, err := d.next()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if c.size >= 0 && c.end() > h.end() {
+			return fmt.Errorf("Error, element %X overflows its parent %X.", c.id, h.id)
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) skip(h header) error {
+	if h.size < 0 {
+		return fmt.Errorf("Error, element %X of unknown size cannot be skipped.", h.id)
+	}
+	return d.discard(h.size)
+}
+
+func (d *decoder) discard(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+	skipped, err := io.CopyN(io.Discard, d.r, n)
+	d.pos += skipped
+	return unexpected(err)
+}
+
+func (d *decoder) value(h header) ([]byte, error) {
+	if h.size < 0 || h.size > maxValueSize {
+		return nil, fmt.Errorf("Error, element %X is too large to be a value.", h.id)
+	}
+	b := make([]byte, h.size)
+	n, err := io.ReadFull(d.r, b)
+	d.pos += int64(n)
+	return b, unexpected(err)
+}
+
+func (d *decoder) uint(h header) (uint64, error) {
+	b, err := d.value(h)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) > 8 {
+		return 0, fmt.Errorf("Error, element %X is too large to be an integer.", h.id)
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (d *decoder) float(h header) (float64, error) {
+	b, err := d.value(h)
+	if err != nil {
+		return 0, err
+	}
+	switch len(b) {
+	case 0:
+		return 0, nil
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	}
+	return 0, fmt.Errorf("Error, element %X has an invalid float size %d.", h.id, len(b))
+}
+
+func (d *decoder) str(h header) (string, error) {
+	b, err := d.value(h)
+	return strings.TrimRight(string(b), "\x00"), err
+}
+
+// unexpected turns the end of the file inside an element into io.ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,186 @@
+package matroska
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+func id(v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	i := 0
+	for i < 3 && b[i] == 0 {
+		i++
+	}
+	return b[i:]
+}
+
+func size(n int) []byte {
+	// Eight bytes sizes exercise the longest variable length integers.
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(n))
+	b[0] = 0x01
+	return b[:]
+}
+
+func el(elementID uint32, children ...[]byte) []byte {
+	data := bytes.Join(children, nil)
+	return append(append(id(elementID), size(len(data))...), data...)
+}
+
+func unknownEl(elementID uint32, children ...[]byte) []byte {
+	return append(append(id(elementID), 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF), bytes.Join(children, nil)...)
+}
+
+func uintEl(elementID uint32, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return el(elementID, b[:])
+}
+
+func floatEl(elementID uint32, v float64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+	return el(elementID, b[:])
+}
+
+func block(elementID uint32, rel int16) []byte {
+	var ts [2]byte
+	binary.BigEndian.PutUint16(ts[:], uint16(rel))
+	return el(elementID, []byte{0x81}, ts[:], []byte{0x80}, bytes.Repeat([]byte{0xAA}, 32))
+}
+
+func opusFile() []byte {
+	return bytes.Join([][]byte{
+		el(idEBML, el(idDocType, []byte("webm"))),
+		unknownEl(idSegment,
+			el(0x114D9B74, []byte{1, 2, 3}), // SeekHead, skipped.
+			el(idInfo,
+				uintEl(idTimestampScale, 1000000),
+				floatEl(idDuration, 10000),
+			),
+			el(idTracks, el(idTrackEntry,
+				uintEl(idTrackNumber, 1),
+				uintEl(idTrackType, 2),
+				el(idCodecID, []byte("A_OPUS")),
+				el(idAudio, floatEl(idSamplingFreq, 48000), uintEl(idChannels, 2)),
+			)),
+			unknownEl(idCluster,
+				uintEl(idTimestamp, 0),
+				block(idSimpleBlock, 0),
+				block(idSimpleBlock, 20),
+			),
+			el(idCluster,
+				uintEl(idTimestamp, 9980),
+				el(idBlockGroup, block(idBlock, 0)),
+			),
+		),
+	}, nil)
+}
+
+func TestParse(t *testing.T) {
+	file := opusFile()
+	info, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.DocType != "webm" || info.Duration != 10*time.Second || info.Truncated {
+		t.Errorf("got doc type %q, duration %v, truncated %v", info.DocType, info.Duration, info.Truncated)
+	}
+	if info.Blocks != 3 || info.FirstTimestamp != 0 || info.LastTimestamp != 9980*time.Millisecond {
+		t.Errorf("got %d blocks from %v to %v", info.Blocks, info.FirstTimestamp, info.LastTimestamp)
+	}
+	if info.Size != int64(len(file)) {
+		t.Errorf("got size %d, want %d", info.Size, len(file))
+	}
+	if len(info.Tracks) != 1 {
+		t.Fatalf("got %d tracks, want 1", len(info.Tracks))
+	}
+	track := info.Tracks[0]
+	if track.Type != TrackTypeAudio || track.Codec() != recording.CodecOPUS ||
+		track.SamplingFrequency != 48000 || track.Channels != 2 {
+		t.Errorf("got track %+v", track)
+	}
+	if info.Container() != recording.ContainerMka {
+		t.Errorf("got container %s", info.Container())
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	file := opusFile()
+	info, err := Parse(bytes.NewReader(file[:len(file)-10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Truncated || info.TruncatedAt != int64(len(file)-10) {
+		t.Errorf("got truncated %v at %d", info.Truncated, info.TruncatedAt)
+	}
+	if info.Blocks != 2 {
+		t.Errorf("got %d blocks before the cut, want 2", info.Blocks)
+	}
+}
+
+func TestParseNotMatroska(t *testing.T) {
+	if _, err := Parse(bytes.NewReader([]byte("\x00\x00\x00\x18ftypmp42"))); err == nil {
+		t.Error("parsed an mp4 file")
+	}
+	if _, err := Parse(bytes.NewReader(nil)); err == nil {
+		t.Error("parsed an empty file")
+	}
+}
+
+func TestVerifyRecording(t *testing.T) {
+	file := opusFile()
+	info, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &recording.RecordingInstance{
+		Type:            recording.TypeAudio,
+		Codec:           recording.CodecOPUS,
+		ContainerFormat: recording.ContainerMka,
+		Duration:        10,
+		Size:            len(file),
+	}
+	if m := VerifyRecording(info, rec); len(m) != 0 {
+		t.Errorf("got mismatches %v", m)
+	}
+
+	rec = &recording.RecordingInstance{
+		Type:            recording.TypeVideo,
+		Codec:           recording.CodecVP8,
+		ContainerFormat: recording.ContainerMkv,
+		Duration:        20,
+		Size:            len(file) + 100,
+	}
+	var got []string
+	for _, m := range VerifyRecording(info, rec) {
+		got = append(got, m.Property)
+	}
+	want := []string{"size", "container_format", "track_type", "codec", "duration"}
+	if len(got) != len(want) {
+		t.Fatalf("got mismatches %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got mismatches %v, want %v", got, want)
+			break
+		}
+	}
+
+	truncated, err := Parse(bytes.NewReader(file[:len(file)-10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := VerifyRecording(truncated, &recording.RecordingInstance{Type: recording.TypeAudio, Duration: 10})
+	if len(m) == 0 || m[0].Property != "complete" {
+		t.Errorf("got mismatches %v for a truncated file", m)
+	}
+}
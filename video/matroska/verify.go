@@ -0,0 +1,80 @@
+package matroska
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+// DurationTolerance is how far the duration of a file may be from its metadata.
+// Twilio rounds recording durations to the second.
+const DurationTolerance = 1500 * time.Millisecond
+
+// Mismatch is a property of a file that differs from its metadata.
+type Mismatch struct {
+	Property string `json:"property"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: expected %s, got %s", m.Property, m.Expected, m.Actual)
+}
+
+// VerifyRecording compares a downloaded recording with its metadata.
+// It checks that the file is complete, holds a single track of the recording's type and codec,
+// and lasts as long as the recording. A PCMU codec is not checked, it has no Matroska codec ID.
+// No mismatch means the file can be trusted.
+func VerifyRecording(info *Info, rec *recording.RecordingInstance) []Mismatch {
+	var ret []Mismatch
+	add := func(property string, expected, actual interface{}) {
+		ret = append(ret, Mismatch{
+			Property: property,
+			Expected: fmt.Sprint(expected),
+			Actual:   fmt.Sprint(actual),
+		})
+	}
+
+	if info.Truncated {
+		add("complete", true, fmt.Sprintf("truncated at byte %d", info.TruncatedAt))
+	}
+	if rec.Size > 0 && info.Size != int64(rec.Size) {
+		add("size", rec.Size, info.Size)
+	}
+	if len(info.Tracks) != 1 {
+		add("tracks", 1, len(info.Tracks))
+	}
+
+	if c := info.Container(); rec.ContainerFormat != "" && c != rec.ContainerFormat {
+		add("container_format", rec.ContainerFormat, c)
+	}
+	wantType := TrackTypeAudio
+	if rec.Type == recording.TypeVideo {
+		wantType = TrackTypeVideo
+	}
+	for _, t := range info.Tracks {
+		if t.Type != wantType {
+			add("track_type", trackTypeName(wantType), trackTypeName(t.Type))
+		}
+		if rec.Codec != "" && rec.Codec != recording.CodecPCMU && t.Codec() != rec.Codec {
+			add("codec", rec.Codec, t.CodecID)
+		}
+	}
+
+	got := info.MediaDuration()
+	if diff := got - rec.GetDuration(); diff > DurationTolerance || diff < -DurationTolerance {
+		add("duration", rec.GetDuration(), got)
+	}
+	return ret
+}
+
+func trackTypeName(t TrackType) string {
+	switch t {
+	case TrackTypeVideo:
+		return "video"
+	case TrackTypeAudio:
+		return "audio"
+	}
+	return fmt.Sprintf("type %d", t)
+}
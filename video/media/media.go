@@ -0,0 +1,93 @@
+// Package media gives one way to read the media of recordings and compositions,
+// whether Twilio stores it or it was uploaded to external storage,
+// where its media_external_location points to.
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Reader is the content of a media. The caller closes it.
+type Reader struct {
+	io.ReadCloser
+
+	// Size of the content in bytes, -1 when unknown.
+	Size int64
+
+	// ContentType as given by the server, empty when unknown.
+	ContentType string
+
+	// External is set when the media was read from external storage.
+	External bool
+}
+
+// Fetcher opens the media stored at an external location.
+type Fetcher interface {
+	Fetch(ctx context.Context, location string) (*Reader, error)
+}
+
+// FetcherFunc adapts a function to a Fetcher.
+type FetcherFunc func(ctx context.Context, location string) (*Reader, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, location string) (*Reader, error) {
+	return f(ctx, location)
+}
+
+// HTTPFetcher fetches external media with a plain GET,
+// for storage that is public or locations that are pre-signed.
+type HTTPFetcher struct {
+	// Client sends the requests, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, location string) (*Reader, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := FromResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	r.External = true
+	return r, nil
+}
+
+// FromResponse returns the body of a successful response as a Reader,
+// or closes it and returns an error holding the start of the body.
+func FromResponse(resp *http.Response) (*Reader, error) {
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf(
+			"unexpected status code, status: %v, message: %s",
+			resp.StatusCode,
+			msg,
+		)
+	}
+	return &Reader{
+		ReadCloser:  resp.Body,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// NoFetcherError is returned for external media when no Fetcher is configured.
+type NoFetcherError struct {
+	Location string
+}
+
+func (e *NoFetcherError) Error() string {
+	return "media is stored externally at " + e.Location + ", but no fetcher is configured"
+}
@@ -0,0 +1,39 @@
+package media
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPFetcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bucket/RT1.mka" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/x-matroska")
+		w.Write([]byte("matroska"))
+	}))
+	defer srv.Close()
+	f := &HTTPFetcher{}
+
+	r, err := f.Fetch(context.Background(), srv.URL+"/bucket/RT1.mka")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "matroska" || r.Size != 8 || !r.External || r.ContentType != "audio/x-matroska" {
+		t.Errorf("got %q, %+v", b, r)
+	}
+
+	if _, err := f.Fetch(context.Background(), srv.URL+"/bucket/missing.mka"); err == nil {
+		t.Error("expected an error for a missing object")
+	}
+}
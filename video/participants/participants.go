@@ -2,22 +2,76 @@ package participants
 
 import "time"
 
+type ParticipantStatus string
+
+const (
+	StatusConnected    ParticipantStatus = "connected"
+	StatusDisconnected ParticipantStatus = "disconnected"
+)
+
 type ParticipantInstance struct {
-	AccountSid  string      `json:"account_sid"`
-	RoomSid     string      `json:"room_sid"`
-	DateCreated time.Time   `json:"date_created"`
-	DateUpdated time.Time   `json:"date_updated"`
-	StartTime   time.Time   `json:"start_time"`
-	EndTime     interface{} `json:"end_time"`
-	Sid         string      `json:"sid"`
-	Identity    string      `json:"identity"`
-	Status      string      `json:"status"`
-	URL         string      `json:"url"`
-	Duration    interface{} `json:"duration"`
-	Links       struct {
+	AccountSid  string            `json:"account_sid"`
+	RoomSid     string            `json:"room_sid"`
+	DateCreated time.Time         `json:"date_created"`
+	DateUpdated time.Time         `json:"date_updated"`
+	StartTime   time.Time         `json:"start_time"`
+	EndTime     *time.Time        `json:"end_time"`
+	Sid         string            `json:"sid"`
+	Identity    string            `json:"identity"`
+	Status      ParticipantStatus `json:"status"`
+	URL         string            `json:"url"`
+	// Duration is in seconds, it is nil while the participant is connected.
+	Duration *int `json:"duration"`
+	Links    struct {
 		PublishedTracks  string `json:"published_tracks"`
 		SubscribedTracks string `json:"subscribed_tracks"`
 		SubscribeRules   string `json:"subscribe_rules"`
 		Anonymize        string `json:"anonymize"`
 	} `json:"links"`
 }
+
+// GetDuration returns how long the participant was connected, zero while it is connected.
+func (p *ParticipantInstance) GetDuration() time.Duration {
+	if p.Duration == nil {
+		return 0
+	}
+	return time.Duration(*p.Duration) * time.Second
+}
+
+type ParticipantList struct {
+	Participants []ParticipantInstance `json:"participants"`
+	Meta         Meta                  `json:"meta"`
+}
+
+type Meta struct {
+	Page            int    `json:"page"`
+	PageSize        int    `json:"page_size"`
+	FirstPageURL    string `json:"first_page_url"`
+	PreviousPageURL string `json:"previous_page_url"`
+	URL             string `json:"url"`
+	NextPageURL     string `json:"next_page_url"`
+	Key             string `json:"key"`
+}
+
+type ParticipantGetParams struct {
+	// Read only the participants with this status. Can be: connected or disconnected.
+	// For in-progress rooms the default status is connected, for completed rooms only disconnected participants are returned.
+	Status *ParticipantStatus `form:"Status,omitempty"`
+
+	// Read only the Participants with this User identity value.
+	Identity *string `form:"Identity,omitempty"`
+
+	// Read only participants that started after this date in ISO 8601 format.
+	DateCreatedAfter *string `form:"DateCreatedAfter,omitempty"`
+
+	// Read only participants that started before this date in ISO 8601 format.
+	DateCreatedBefore *string `form:"DateCreatedBefore,omitempty"`
+
+	// How many resources to return in each list page. The default is 50, and the maximum is 1000.
+	PageSize *uint `form:"PageSize,omitempty"`
+}
+
+type ParticipantUpdateParams struct {
+	// The new status of the resource. Set to disconnected to remove the participant from the room.
+	Status ParticipantStatus `form:"Status"`
+}
@@ -0,0 +1,35 @@
+package participants
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParticipantInstanceNullableFields(t *testing.T) {
+	var connected ParticipantInstance
+	if err := json.Unmarshal([]byte(`{"status":"connected","end_time":null,"duration":null}`), &connected); err != nil {
+		t.Fatal(err)
+	}
+	if connected.EndTime != nil || connected.Duration != nil || connected.GetDuration() != 0 {
+		t.Errorf("connected participant has end time %v and duration %v", connected.EndTime, connected.Duration)
+	}
+
+	var disconnected ParticipantInstance
+	if err := json.Unmarshal([]byte(`{
+		"status": "disconnected",
+		"end_time": "2015-07-30T20:00:00Z",
+		"duration": 3600
+	}`), &disconnected); err != nil {
+		t.Fatal(err)
+	}
+	if disconnected.Status != StatusDisconnected {
+		t.Errorf("got status %q", disconnected.Status)
+	}
+	if disconnected.EndTime == nil || !disconnected.EndTime.Equal(time.Date(2015, 7, 30, 20, 0, 0, 0, time.UTC)) {
+		t.Errorf("got end time %v", disconnected.EndTime)
+	}
+	if got := disconnected.GetDuration(); got != time.Hour {
+		t.Errorf("got duration %v, want %v", got, time.Hour)
+	}
+}
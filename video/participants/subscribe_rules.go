@@ -0,0 +1,120 @@
+package participants
+
+import (
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/rooms"
+)
+
+// More info https://www.twilio.com/docs/video/api/track-subscriptions
+
+type RuleType = rooms.RuleType
+
+const (
+	RuleTypeInclude = rooms.RuleTypeInclude
+	RuleTypeExclude = rooms.RuleTypeExclude
+)
+
+// SubscribeRule includes or excludes the tracks matching all of its filters.
+// All matches every track and cannot be combined with the other filters.
+type SubscribeRule struct {
+	Type RuleType `json:"type"`
+	All  bool     `json:"all,omitempty"`
+
+	// One of rooms.TrackKindAudio, rooms.TrackKindVideo or rooms.TrackKindData.
+	Kind string `json:"kind,omitempty"`
+
+	// The identity or SID of the participant publishing the track.
+	Publisher string `json:"publisher,omitempty"`
+
+	// The name or SID of the track.
+	Track string `json:"track,omitempty"`
+
+	// Only given in responses.
+	Priority string `json:"priority,omitempty"`
+}
+
+// DefaultSubscribeRules are the rules of a participant nobody set rules for.
+var DefaultSubscribeRules = []SubscribeRule{{Type: RuleTypeInclude, All: true}}
+
+type SubscribeRules struct {
+	ParticipantSid string          `json:"participant_sid"`
+	RoomSid        string          `json:"room_sid"`
+	Rules          []SubscribeRule `json:"rules"`
+	DateCreated    *time.Time      `json:"date_created"`
+	DateUpdated    *time.Time      `json:"date_updated"`
+}
+
+// TrackRule returns the rule without its priority, as the room's rules select tracks.
+func (r SubscribeRule) TrackRule() rooms.TrackRule {
+	return rooms.TrackRule{
+		Type:      r.Type,
+		All:       r.All,
+		Kind:      r.Kind,
+		Publisher: r.Publisher,
+		Track:     r.Track,
+	}
+}
+
+func trackRules(rules []SubscribeRule) []rooms.TrackRule {
+	ret := make([]rooms.TrackRule, len(rules))
+	for i, r := range rules {
+		ret[i] = r.TrackRule()
+	}
+	return ret
+}
+
+// ValidateSubscribeRules checks every rule and returns all violations at once
+// as video.ValidationErrors of *video.RuleError.
+func ValidateSubscribeRules(rules []SubscribeRule) error {
+	return rooms.ValidateRules(trackRules(rules))
+}
+
+// RoomTrack is a published track along with its publisher's identity,
+// which the publisher filter also matches.
+type RoomTrack struct {
+	PublishedTrack
+	PublisherIdentity string
+}
+
+// RuleTrack returns the track as the room's rules match it.
+func (t RoomTrack) RuleTrack() rooms.RuleTrack {
+	return rooms.RuleTrack{
+		Sid:               t.Sid,
+		Name:              t.Name,
+		Kind:              t.Kind,
+		PublisherSid:      t.ParticipantSid,
+		PublisherIdentity: t.PublisherIdentity,
+	}
+}
+
+// Matches reports whether the track passes all of the rule's filters.
+func (r SubscribeRule) Matches(track RoomTrack) bool {
+	return r.TrackRule().Matches(track.RuleTrack())
+}
+
+// SubscribedTracksOf predicts the tracks the subscriber receives out of the room's published tracks.
+// The rules apply in order, so a later matching rule overrides an earlier one,
+// and a track no rule matches is not subscribed.
+// Participants never receive their own tracks, and disabled tracks are still subscribed
+// since Twilio only stops sending their media.
+func SubscribedTracksOf(subscriberSid string, rules []SubscribeRule, tracks []RoomTrack) []RoomTrack {
+	var others []RoomTrack
+	for _, track := range tracks {
+		if track.ParticipantSid != subscriberSid {
+			others = append(others, track)
+		}
+	}
+	return includedTracks(rules, others)
+}
+
+func includedTracks(rules []SubscribeRule, tracks []RoomTrack) []RoomTrack {
+	trs := trackRules(rules)
+	var ret []RoomTrack
+	for _, track := range tracks {
+		if rooms.Included(trs, track.RuleTrack()) {
+			ret = append(ret, track)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,110 @@
+package participants
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/rooms"
+)
+
+func roomTrack(publisherSid, identity, name, kind string) RoomTrack {
+	return RoomTrack{
+		PublishedTrack: PublishedTrack{
+			Sid:            "MT" + identity + name,
+			ParticipantSid: publisherSid,
+			Name:           name,
+			Kind:           kind,
+		},
+		PublisherIdentity: identity,
+	}
+}
+
+func TestValidateSubscribeRules(t *testing.T) {
+	if err := ValidateSubscribeRules(DefaultSubscribeRules); err != nil {
+		t.Errorf("default rules: %v", err)
+	}
+
+	err := ValidateSubscribeRules([]SubscribeRule{
+		{Type: RuleTypeInclude, Publisher: "teacher"},
+		{Type: "only", All: true, Kind: rooms.TrackKindVideo},
+		{Type: RuleTypeExclude},
+		{Type: RuleTypeExclude, Kind: "screen"},
+	})
+	var errs video.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+	wantIndexes := []int{1, 1, 2, 3}
+	if len(errs) != len(wantIndexes) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(wantIndexes), err)
+	}
+	for i, e := range errs {
+		var ruleErr *video.RuleError
+		if !errors.As(e, &ruleErr) || ruleErr.Index != wantIndexes[i] {
+			t.Errorf("error %d: got %v, want rule %d", i, e, wantIndexes[i])
+		}
+	}
+}
+
+func TestSubscribedTracksOf(t *testing.T) {
+	tracks := []RoomTrack{
+		roomTrack("PA1", "teacher", "camera", rooms.TrackKindVideo),
+		roomTrack("PA1", "teacher", "mic", rooms.TrackKindAudio),
+		roomTrack("PA2", "student1", "camera", rooms.TrackKindVideo),
+		roomTrack("PA2", "student1", "mic", rooms.TrackKindAudio),
+		roomTrack("PA3", "student2", "camera", rooms.TrackKindVideo),
+	}
+
+	tests := []struct {
+		name  string
+		rules []SubscribeRule
+		want  []string
+	}{
+		{
+			name:  "default",
+			rules: DefaultSubscribeRules,
+			want:  []string{"teacher/camera", "teacher/mic", "student2/camera"},
+		},
+		{
+			name:  "no rules",
+			rules: nil,
+			want:  nil,
+		},
+		{
+			name: "teacher video and all audio",
+			rules: []SubscribeRule{
+				{Type: RuleTypeInclude, Kind: rooms.TrackKindAudio},
+				{Type: RuleTypeInclude, Publisher: "teacher", Kind: rooms.TrackKindVideo},
+			},
+			want: []string{"teacher/camera", "teacher/mic"},
+		},
+		{
+			name: "later rule overrides",
+			rules: []SubscribeRule{
+				{Type: RuleTypeInclude, All: true},
+				{Type: RuleTypeExclude, Kind: rooms.TrackKindVideo},
+				{Type: RuleTypeInclude, Publisher: "PA1", Track: "camera"},
+			},
+			want: []string{"teacher/camera", "teacher/mic"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SubscribedTracksOf("PA2", tt.rules, tracks)
+			var names []string
+			for _, track := range got {
+				names = append(names, track.PublisherIdentity+"/"+track.Name)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("got %v, want %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", names, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package participants
+
+import "time"
+
+// More info https://www.twilio.com/docs/video/api/track-subscriptions
+
+// PublishedTrack is a track the participant is sending to the room.
+type PublishedTrack struct {
+	Sid            string    `json:"sid"`
+	ParticipantSid string    `json:"participant_sid"`
+	RoomSid        string    `json:"room_sid"`
+	Name           string    `json:"name"`
+	DateCreated    time.Time `json:"date_created"`
+	DateUpdated    time.Time `json:"date_updated"`
+	Enabled        bool      `json:"enabled"`
+	// One of rooms.TrackKindAudio, rooms.TrackKindVideo or rooms.TrackKindData.
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
+
+type PublishedTrackList struct {
+	PublishedTracks []PublishedTrack `json:"published_tracks"`
+	Meta            Meta             `json:"meta"`
+}
+
+// SubscribedTrack is a track of another participant the participant is receiving.
+type SubscribedTrack struct {
+	Sid            string    `json:"sid"`
+	ParticipantSid string    `json:"participant_sid"`
+	PublisherSid   string    `json:"publisher_sid"`
+	SubscriberSid  string    `json:"subscriber_sid"`
+	RoomSid        string    `json:"room_sid"`
+	Name           string    `json:"name"`
+	DateCreated    time.Time `json:"date_created"`
+	DateUpdated    time.Time `json:"date_updated"`
+	Enabled        bool      `json:"enabled"`
+	// One of rooms.TrackKindAudio, rooms.TrackKindVideo or rooms.TrackKindData.
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
+
+type SubscribedTrackList struct {
+	SubscribedTracks []SubscribedTrack `json:"subscribed_tracks"`
+	Meta             Meta              `json:"meta"`
+}
@@ -0,0 +1,321 @@
+// Package placement predicts where Twilio places each video source of a room
+// in the grid cells of a video layout's regions, before paying for a composition.
+//
+// The simulation follows Twilio's documented placement rules:
+// sources are placed in the order they start, simultaneous starts follow
+// the order of the first entry of video_sources they match, then their track name.
+// A new source takes the free cell with the lowest index, skipping cells_excluded.
+// When the grid is full, reuse decides what happens:
+//
+//	show_oldest: the new source waits until a cell is freed by a source that ends.
+//	show_newest: the new source replaces the source that started first.
+//	none: a cell is never reused after its source ends.
+//
+// A region without max_columns and max_rows grows to fit every source.
+package placement
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+// Track is a video track of the room's timeline.
+type Track struct {
+	// Name of the track, matched against the layout's wildcard sources.
+	Name string
+
+	// Sid of the track or of its recording, matched exactly.
+	Sid string
+
+	// ParticipantSid of the track's publisher, matched exactly.
+	ParticipantSid string
+
+	// Start and End relative to the start of the room.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Placement is a source shown in a region's cell.
+type Placement struct {
+	Region string
+	Cell   int
+	Bounds video.Rect
+	Track  Track
+}
+
+// Hidden is a source that matches a region but has no cell.
+type Hidden struct {
+	Region string
+	Track  Track
+}
+
+// Slice is a period of the room during which the placement does not change.
+type Slice struct {
+	Start      time.Duration
+	End        time.Duration
+	Placements []Placement
+	Hidden     []Hidden
+}
+
+// Simulate returns the placement of the tracks for every change in the room's timeline.
+func Simulate(l *video.VideoLayout, tracks []Track) ([]Slice, error) {
+	if l == nil {
+		return nil, errors.New("Error, video layout must not be nil.")
+	}
+	res := l.GetResolution()
+	if res.IsZero() {
+		res = video.DefaultResolution()
+	}
+
+	var regions []*regionState
+	for _, r := range l.GetRegions() {
+		if r == nil || r.Prop == nil {
+			return nil, errors.New("Error, the region must have properties.")
+		}
+		regions = append(regions, newRegionState(r, res))
+	}
+
+	times := map[time.Duration]bool{}
+	for _, tr := range tracks {
+		if tr.End < tr.Start {
+			return nil, errors.New("Error, track " + tr.Name + " ends before it starts.")
+		}
+		times[tr.Start] = true
+		times[tr.End] = true
+	}
+	var events []time.Duration
+	for at := range times {
+		events = append(events, at)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+
+	var slices []Slice
+	for i, at := range events {
+		for _, rs := range regions {
+			rs.step(at, tracks)
+		}
+		if i == len(events)-1 {
+			break
+		}
+
+		s := Slice{Start: at, End: events[i+1]}
+		for _, rs := range regions {
+			p, h := rs.snapshot()
+			s.Placements = append(s.Placements, p...)
+			s.Hidden = append(s.Hidden, h...)
+		}
+		slices = append(slices, s)
+	}
+	return slices, nil
+}
+
+// entry is a track matching a region, with its priority among simultaneous starts.
+type entry struct {
+	track    Track
+	priority int
+}
+
+type regionState struct {
+	region   *video.Region
+	bounds   video.Rect
+	reuse    string
+	capacity int // Number of cells, or -1 when the grid grows with the sources.
+	excluded map[int]bool
+
+	cells   map[int]*entry
+	used    map[int]bool
+	waiting []*entry
+}
+
+func newRegionState(r *video.Region, res video.Resolution) *regionState {
+	rs := &regionState{
+		region:   r,
+		bounds:   r.Bounds(res),
+		reuse:    video.ReuseShowOldest,
+		capacity: -1,
+		excluded: map[int]bool{},
+		cells:    map[int]*entry{},
+		used:     map[int]bool{},
+	}
+	if r.Prop.Reuse != nil {
+		rs.reuse = *r.Prop.Reuse
+	}
+	if r.Prop.MaxColumns != nil && r.Prop.MaxRows != nil {
+		rs.capacity = int(*r.Prop.MaxColumns) * int(*r.Prop.MaxRows)
+	}
+	for _, c := range r.Prop.CellsExcluded {
+		rs.excluded[int(c)] = true
+	}
+	return rs
+}
+
+// match reports whether the track belongs to the region,
+// and its priority given by the first video source entry it matches.
+func (rs *regionState) match(tr Track) (int, bool) {
+	for _, ex := range rs.region.Prop.VideoSourcesExcluded {
+		if matchTrack(ex, tr) {
+			return 0, false
+		}
+	}
+	for i, src := range rs.region.Prop.VideoSources {
+		if matchTrack(src, tr) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func matchTrack(pattern string, tr Track) bool {
+	return (tr.Sid != "" && pattern == tr.Sid) ||
+		(tr.ParticipantSid != "" && pattern == tr.ParticipantSid) ||
+		video.MatchSource(pattern, tr.Name)
+}
+
+// step applies the tracks ending and starting at the given time.
+func (rs *regionState) step(at time.Duration, tracks []Track) {
+	for idx, e := range rs.cells {
+		if e.track.End == at {
+			delete(rs.cells, idx)
+		}
+	}
+	waiting := rs.waiting[:0]
+	for _, e := range rs.waiting {
+		if e.track.End != at {
+			waiting = append(waiting, e)
+		}
+	}
+	rs.waiting = waiting
+
+	var starting []*entry
+	for _, tr := range tracks {
+		if tr.Start != at || tr.End == at {
+			continue
+		}
+		if priority, ok := rs.match(tr); ok {
+			starting = append(starting, &entry{track: tr, priority: priority})
+		}
+	}
+	sort.SliceStable(starting, func(i, j int) bool {
+		if starting[i].priority != starting[j].priority {
+			return starting[i].priority < starting[j].priority
+		}
+		return starting[i].track.Name < starting[j].track.Name
+	})
+
+	switch rs.reuse {
+	case video.ReuseShowNewest:
+		for _, e := range starting {
+			if !rs.place(e) {
+				rs.evictOldest(e)
+			}
+		}
+		// Sources pushed out earlier come back newest first when cells are freed.
+		for i := len(rs.waiting) - 1; i >= 0; i-- {
+			if rs.place(rs.waiting[i]) {
+				rs.waiting = append(rs.waiting[:i], rs.waiting[i+1:]...)
+			}
+		}
+	default:
+		// Waiting sources started earlier, so they go before the new ones.
+		pending := append(rs.waiting, starting...)
+		rs.waiting = nil
+		for _, e := range pending {
+			if !rs.place(e) {
+				rs.waiting = append(rs.waiting, e)
+			}
+		}
+	}
+}
+
+// place puts the entry in the free cell with the lowest index, if any.
+func (rs *regionState) place(e *entry) bool {
+	for idx := 0; rs.capacity < 0 || idx < rs.capacity; idx++ {
+		if rs.excluded[idx] || rs.cells[idx] != nil {
+			continue
+		}
+		if rs.reuse == video.ReuseNone && rs.used[idx] {
+			continue
+		}
+		rs.cells[idx] = e
+		rs.used[idx] = true
+		return true
+	}
+	return false
+}
+
+// evictOldest gives the cell of the source that started first to the entry.
+func (rs *regionState) evictOldest(e *entry) {
+	oldest := -1
+	for idx, c := range rs.cells {
+		if oldest < 0 || c.track.Start < rs.cells[oldest].track.Start ||
+			(c.track.Start == rs.cells[oldest].track.Start && idx < oldest) {
+			oldest = idx
+		}
+	}
+	if oldest < 0 {
+		rs.waiting = append(rs.waiting, e)
+		return
+	}
+	rs.waiting = append(rs.waiting, rs.cells[oldest])
+	rs.cells[oldest] = e
+}
+
+func (rs *regionState) snapshot() ([]Placement, []Hidden) {
+	var idxs []int
+	for idx := range rs.cells {
+		idxs = append(idxs, idx)
+	}
+	sort.Ints(idxs)
+
+	cells := rs.grid(idxs)
+	var placements []Placement
+	for _, idx := range idxs {
+		placements = append(placements, Placement{
+			Region: rs.region.Name,
+			Cell:   idx,
+			Bounds: cells[idx],
+			Track:  rs.cells[idx].track,
+		})
+	}
+	var hidden []Hidden
+	for _, e := range rs.waiting {
+		hidden = append(hidden, Hidden{Region: rs.region.Name, Track: e.track})
+	}
+	return placements, hidden
+}
+
+// grid returns the region's cells. A grid without a fixed size is shaped
+// near square to fit the highest occupied cell.
+func (rs *regionState) grid(idxs []int) []video.Rect {
+	n := 1
+	if len(idxs) > 0 {
+		n = idxs[len(idxs)-1] + 1
+	}
+
+	p := rs.region.Prop
+	var cols, rows int
+	switch {
+	case p.MaxColumns != nil && p.MaxRows != nil:
+		cols, rows = int(*p.MaxColumns), int(*p.MaxRows)
+	case p.MaxColumns != nil:
+		cols = int(*p.MaxColumns)
+		if n < cols {
+			cols = n
+		}
+		rows = (n + cols - 1) / cols
+	case p.MaxRows != nil:
+		rows = int(math.Ceil(math.Sqrt(float64(n))))
+		if int(*p.MaxRows) < rows {
+			rows = int(*p.MaxRows)
+		}
+		cols = (n + rows - 1) / rows
+	default:
+		cols = int(math.Ceil(math.Sqrt(float64(n))))
+		rows = (n + cols - 1) / cols
+	}
+	return rs.bounds.Cells(cols, rows)
+}
@@ -0,0 +1,179 @@
+package placement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+func gridLayout(t *testing.T, reuse video.ReusePolicy, excluded ...uint32) *video.VideoLayout {
+	l, err := video.NewVideoLayout(video.VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Excluded cells are added on top of the two usable ones.
+	columns := uint16(2 + len(excluded))
+	if _, err := l.NewRegion("grid").
+		Grid(columns, 1).
+		ExcludeCells(excluded...).
+		Reuse(reuse).
+		Sources("student*").
+		ExcludeSources("studentTA").
+		Add(); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func sec(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+var classroom = []Track{
+	{Name: "studentA", Start: sec(0), End: sec(30)},
+	{Name: "studentB", Start: sec(5), End: sec(20)},
+	{Name: "studentC", Start: sec(10), End: sec(40)},
+	{Name: "studentTA", Start: sec(0), End: sec(40)},
+	{Name: "teacher", Start: sec(0), End: sec(40)},
+}
+
+// shown returns the track name of every cell, "" for empty cells.
+func shown(s Slice, cells int) []string {
+	names := make([]string, cells)
+	for _, p := range s.Placements {
+		names[p.Cell] = p.Track.Name
+	}
+	return names
+}
+
+func checkSlices(t *testing.T, slices []Slice, cells int, want [][]string) {
+	t.Helper()
+	if len(slices) != len(want) {
+		t.Fatalf("got %d slices, want %d", len(slices), len(want))
+	}
+	for i, s := range slices {
+		got := shown(s, cells)
+		for c := range got {
+			if got[c] != want[i][c] {
+				t.Errorf("slice %v-%v: got %v, want %v", s.Start, s.End, got, want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSimulateShowOldest(t *testing.T) {
+	slices, err := Simulate(gridLayout(t, video.ReusePolicyShowOldest), classroom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// studentC waits until studentB leaves at 20s.
+	checkSlices(t, slices, 2, [][]string{
+		{"studentA", ""},
+		{"studentA", "studentB"},
+		{"studentA", "studentB"},
+		{"studentA", "studentC"},
+		{"", "studentC"},
+	})
+	if len(slices[2].Hidden) != 1 || slices[2].Hidden[0].Track.Name != "studentC" {
+		t.Errorf("studentC must be hidden between 10s and 20s, got %v", slices[2].Hidden)
+	}
+}
+
+func TestSimulateShowNewest(t *testing.T) {
+	slices, err := Simulate(gridLayout(t, video.ReusePolicyShowNewest), classroom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// studentC replaces studentA, the oldest, who comes back when studentB leaves.
+	checkSlices(t, slices, 2, [][]string{
+		{"studentA", ""},
+		{"studentA", "studentB"},
+		{"studentC", "studentB"},
+		{"studentC", "studentA"},
+		{"studentC", ""},
+	})
+}
+
+func TestSimulateReuseNone(t *testing.T) {
+	slices, err := Simulate(gridLayout(t, video.ReusePolicyNone), classroom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cell studentB leaves is never used again.
+	checkSlices(t, slices, 2, [][]string{
+		{"studentA", ""},
+		{"studentA", "studentB"},
+		{"studentA", "studentB"},
+		{"studentA", ""},
+		{"", ""},
+	})
+}
+
+func TestSimulateExcludedCells(t *testing.T) {
+	slices, err := Simulate(gridLayout(t, video.ReusePolicyShowOldest, 0), classroom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlices(t, slices, 3, [][]string{
+		{"", "studentA", ""},
+		{"", "studentA", "studentB"},
+		{"", "studentA", "studentB"},
+		{"", "studentA", "studentC"},
+		{"", "", "studentC"},
+	})
+
+	p := slices[1].Placements[1]
+	if p.Bounds.X != 426 || p.Bounds.Width != 214 {
+		t.Errorf("cell 2 of a 3x1 grid on 640 pixels, got %+v", p.Bounds)
+	}
+}
+
+func TestSimulateGrowingGrid(t *testing.T) {
+	l, err := video.NewVideoLayout(video.VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.NewRegion("all").Sources("*").Add(); err != nil {
+		t.Fatal(err)
+	}
+	slices, err := Simulate(l, classroom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// At 10s all five tracks are shown in a 3x2 grid.
+	s := slices[2]
+	if len(s.Placements) != 5 || len(s.Hidden) != 0 {
+		t.Fatalf("got %d placements and %d hidden", len(s.Placements), len(s.Hidden))
+	}
+	if b := s.Placements[4].Bounds; b.X != 213 || b.Y != 240 {
+		t.Errorf("fifth cell at %d,%d", b.X, b.Y)
+	}
+}
+
+func TestTracksFromRecordings(t *testing.T) {
+	roomStart := time.Date(2021, 5, 18, 10, 0, 0, 0, time.UTC)
+	recs := []recording.RecordingInstance{
+		{Sid: "RT1", Type: "video", TrackName: "teacher", SourceSid: "MT1", Duration: 60,
+			DateCreated: roomStart.Add(5 * time.Second), Offset: 1005000},
+		{Sid: "RT2", Type: "audio", TrackName: "teacher-mic", SourceSid: "MT2", Duration: 60,
+			DateCreated: roomStart.Add(5 * time.Second), Offset: 1005000},
+		// Created in the same second as the teacher's, its media starts 750ms later.
+		{Sid: "RT3", Type: "video", TrackName: "student", SourceSid: "MT3", Duration: 30,
+			DateCreated: roomStart.Add(5 * time.Second), Offset: 1005750},
+		{Sid: "RT4", Type: "video", TrackName: "gone", SourceSid: "MT4", Duration: 30,
+			DateCreated: roomStart, Status: recording.StatusDeleted},
+	}
+	tracks := TracksFromRecordings(recs, roomStart)
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want only the video ones with media", len(tracks))
+	}
+	if tracks[0].Start != sec(5) || tracks[0].End != sec(65) || tracks[0].Sid != "MT1" {
+		t.Errorf("got %+v", tracks[0])
+	}
+	if tracks[1].Start != 5750*time.Millisecond || tracks[1].End != 35750*time.Millisecond {
+		t.Errorf("got %+v", tracks[1])
+	}
+}
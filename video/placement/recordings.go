@@ -0,0 +1,39 @@
+package placement
+
+import (
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+	"github.com/matthxwpavin/twilio-compositions/video/timeline"
+)
+
+// TracksFromRecordings turns the video recordings of a room into its timeline,
+// so wildcard sources are resolved against the room's real track names.
+// The recordings are placed the way timeline.Align places them, on their offsets,
+// and keep their order. Audio recordings and the ones without media are skipped.
+func TracksFromRecordings(recs []recording.RecordingInstance, roomStart time.Time) []Track {
+	aligned := map[string]*timeline.Track{}
+	for _, p := range timeline.Align("", roomStart, recs).Participants {
+		for _, pair := range p.Pairs {
+			if pair.Video != nil {
+				aligned[pair.Video.RecordingSid] = pair.Video
+			}
+		}
+	}
+
+	var tracks []Track
+	for _, rec := range recs {
+		t, ok := aligned[rec.Sid]
+		if !ok {
+			continue
+		}
+		tracks = append(tracks, Track{
+			Name:           rec.TrackName,
+			Sid:            rec.SourceSid,
+			ParticipantSid: rec.GroupingSids.ParticipantSid,
+			Start:          t.Start(),
+			End:            t.End(),
+		})
+	}
+	return tracks
+}
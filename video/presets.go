@@ -0,0 +1,358 @@
+package video
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Sources is the set of video sources shown in a preset's region.
+// The patterns can be track names with wildcards, track SIDs or participant SIDs.
+// See RegionProp.VideoSources for the accepted values.
+type Sources struct {
+	Include []string
+	Exclude []string
+}
+
+// AllSources matches every video track of the room.
+var AllSources = Sources{Include: []string{"*"}}
+
+type PresetOptions struct {
+	// Margin in pixels kept between the composition's edges and the regions,
+	// as well as between adjacent regions.
+	Margin uint16
+}
+
+type Corner int
+
+const (
+	// CornerDefault is the zero Corner, it places the inset at the bottom right.
+	CornerDefault Corner = iota
+	CornerTopLeft
+	CornerTopRight
+	CornerBottomLeft
+	CornerBottomRight
+)
+
+type PictureInPictureOptions struct {
+	PresetOptions
+
+	// Corner of the composition where the inset is placed.
+	// Defaults to CornerBottomRight.
+	Corner Corner
+
+	// Size of the inset in pixels.
+	// Defaults to a quarter of the composition's width and height.
+	InsetWidth  uint16
+	InsetHeight uint16
+}
+
+type SpeakerOptions struct {
+	PresetOptions
+
+	// Height of the bottom strip in pixels. Defaults to a quarter of the composition's height.
+	StripHeight uint16
+
+	// Maximum number of participants shown side by side in the strip. Defaults to 4.
+	StripColumns uint16
+}
+
+type PresenterOptions struct {
+	PresetOptions
+
+	// Width of the screen-share region in pixels.
+	// Defaults to three quarters of the composition's width.
+	ScreenWidth uint16
+}
+
+// NewGridLayout creates a layout with one region named "grid"
+// sized to place n video sources in a near square grid.
+func NewGridLayout(resolution Resolution, n int, sources Sources, opts *PresetOptions) (*VideoLayout, error) {
+	if n < 1 {
+		return nil, errors.New("Error, grid must have at least one participant.")
+	}
+	l, area, err := newPresetLayout(resolution, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := uint16(math.Ceil(math.Sqrt(float64(n))))
+	rows := uint16((n + int(cols) - 1) / int(cols))
+	grid := area.region("grid", sources)
+	grid.Prop.MaxColumns = &cols
+	grid.Prop.MaxRows = &rows
+	grid.Prop.Reuse = stringPtr(ReuseShowOldest)
+
+	if err := l.addPresetRegions(grid); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewSpeakerLayout creates a layout with a large "speaker" region on top
+// and a "strip" region along the bottom for everyone else.
+// The speaker's sources are excluded from the strip, except the ones with a wildcard,
+// which would also keep everyone else out of it.
+func NewSpeakerLayout(
+	resolution Resolution,
+	speaker, strip Sources,
+	opts *SpeakerOptions,
+) (*VideoLayout, error) {
+	if opts == nil {
+		opts = &SpeakerOptions{}
+	}
+	l, area, err := newPresetLayout(resolution, &opts.PresetOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	stripHeight := opts.StripHeight
+	if stripHeight == 0 {
+		stripHeight = area.height / 4
+	}
+	if stripHeight+area.margin >= area.height {
+		return nil, errors.New("Error, strip height leaves no room for the speaker.")
+	}
+	stripColumns := opts.StripColumns
+	if stripColumns == 0 {
+		stripColumns = 4
+	}
+
+	top, bottom := area.splitHorizontal(area.height - stripHeight - area.margin)
+	speakerReg := top.region("speaker", speaker)
+	speakerReg.Prop.MaxColumns = uint16Ptr(1)
+	speakerReg.Prop.MaxRows = uint16Ptr(1)
+	speakerReg.Prop.Reuse = stringPtr(ReuseShowNewest)
+
+	strip.Exclude = append([]string{}, strip.Exclude...)
+	for _, src := range speaker.Include {
+		if !strings.Contains(src, "*") {
+			strip.Exclude = append(strip.Exclude, src)
+		}
+	}
+	stripReg := bottom.region("strip", strip)
+	stripReg.Prop.MaxColumns = &stripColumns
+	stripReg.Prop.MaxRows = uint16Ptr(1)
+	stripReg.Prop.Reuse = stringPtr(ReuseShowOldest)
+
+	if err := l.addPresetRegions(speakerReg, stripReg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewPictureInPictureLayout creates a "main" region covering the composition
+// and a smaller "inset" region stacked on top of it in one of the corners.
+func NewPictureInPictureLayout(
+	resolution Resolution,
+	main, inset Sources,
+	opts *PictureInPictureOptions,
+) (*VideoLayout, error) {
+	if opts == nil {
+		opts = &PictureInPictureOptions{}
+	}
+	l, area, err := newPresetLayout(resolution, &opts.PresetOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	insetW, insetH := opts.InsetWidth, opts.InsetHeight
+	if insetW == 0 {
+		insetW = area.width / 4
+	}
+	if insetH == 0 {
+		insetH = area.height / 4
+	}
+	if insetW+2*area.margin > area.width || insetH+2*area.margin > area.height {
+		return nil, errors.New("Error, inset does not fit into the composition.")
+	}
+
+	insetArea := presetArea{width: insetW, height: insetH}
+	switch opts.Corner {
+	case CornerTopLeft:
+		insetArea.x, insetArea.y = area.x+area.margin, area.y+area.margin
+	case CornerTopRight:
+		insetArea.x, insetArea.y = area.right()-area.margin-insetW, area.y+area.margin
+	case CornerBottomLeft:
+		insetArea.x, insetArea.y = area.x+area.margin, area.bottom()-area.margin-insetH
+	case CornerDefault, CornerBottomRight:
+		insetArea.x, insetArea.y = area.right()-area.margin-insetW, area.bottom()-area.margin-insetH
+	default:
+		return nil, errors.New("Error, unknown picture-in-picture corner.")
+	}
+
+	mainReg := area.region("main", main)
+	mainReg.Prop.ZPos = int16Ptr(1)
+	mainReg.Prop.MaxColumns = uint16Ptr(1)
+	mainReg.Prop.MaxRows = uint16Ptr(1)
+	mainReg.Prop.Reuse = stringPtr(ReuseShowNewest)
+
+	insetReg := insetArea.region("inset", inset)
+	insetReg.Prop.ZPos = int16Ptr(2)
+	insetReg.Prop.MaxColumns = uint16Ptr(1)
+	insetReg.Prop.MaxRows = uint16Ptr(1)
+	insetReg.Prop.Reuse = stringPtr(ReuseShowNewest)
+
+	if err := l.addPresetRegions(mainReg, insetReg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewSideBySideLayout splits the composition into
+// a "left" and a "right" region of the same size.
+func NewSideBySideLayout(
+	resolution Resolution,
+	left, right Sources,
+	opts *PresetOptions,
+) (*VideoLayout, error) {
+	l, area, err := newPresetLayout(resolution, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	leftArea, rightArea := area.splitVertical((area.width - area.margin) / 2)
+	leftReg := leftArea.region("left", left)
+	leftReg.Prop.Reuse = stringPtr(ReuseShowOldest)
+	rightReg := rightArea.region("right", right)
+	rightReg.Prop.Reuse = stringPtr(ReuseShowOldest)
+
+	if err := l.addPresetRegions(leftReg, rightReg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewPresenterLayout creates a large "screen" region for the screen-share track
+// and a "presenter" region for the presenter's camera on its right.
+func NewPresenterLayout(
+	resolution Resolution,
+	screen, presenter Sources,
+	opts *PresenterOptions,
+) (*VideoLayout, error) {
+	if opts == nil {
+		opts = &PresenterOptions{}
+	}
+	l, area, err := newPresetLayout(resolution, &opts.PresetOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	screenWidth := opts.ScreenWidth
+	if screenWidth == 0 {
+		screenWidth = (area.width - area.margin) * 3 / 4
+	}
+	if screenWidth+area.margin >= area.width {
+		return nil, errors.New("Error, screen width leaves no room for the presenter.")
+	}
+
+	screenArea, side := area.splitVertical(screenWidth)
+	// Keep the presenter's camera in 4:3 at the top of the side column.
+	if h := side.width * 3 / 4; h < side.height {
+		side.height = h
+	}
+
+	screenReg := screenArea.region("screen", screen)
+	screenReg.Prop.MaxColumns = uint16Ptr(1)
+	screenReg.Prop.MaxRows = uint16Ptr(1)
+	screenReg.Prop.Reuse = stringPtr(ReuseShowNewest)
+
+	presenterReg := side.region("presenter", presenter)
+	presenterReg.Prop.MaxColumns = uint16Ptr(1)
+	presenterReg.Prop.MaxRows = uint16Ptr(1)
+	presenterReg.Prop.Reuse = stringPtr(ReuseShowNewest)
+
+	if err := l.addPresetRegions(screenReg, presenterReg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// presetArea is a rectangle of the composition available to a preset's regions.
+type presetArea struct {
+	x, y, width, height uint16
+	margin              uint16
+}
+
+func newPresetLayout(resolution Resolution, opts *PresetOptions) (*VideoLayout, presetArea, error) {
+	if opts == nil {
+		opts = &PresetOptions{}
+	}
+	l, err := NewVideoLayout(resolution)
+	if err != nil {
+		return nil, presetArea{}, err
+	}
+
+	m := opts.Margin
+	if 2*uint32(m)+XPosDefaultInPix > uint32(l.Resolution.Width) ||
+		2*uint32(m)+YPosDefaultInPix > uint32(l.Resolution.Height) {
+		return nil, presetArea{}, errors.New("Error, margin is too large for the resolution.")
+	}
+	return l, presetArea{
+		x:      m,
+		y:      m,
+		width:  l.Resolution.Width - 2*m,
+		height: l.Resolution.Height - 2*m,
+		margin: m,
+	}, nil
+}
+
+func (a presetArea) right() uint16 {
+	return a.x + a.width
+}
+
+func (a presetArea) bottom() uint16 {
+	return a.y + a.height
+}
+
+// splitHorizontal cuts the area into a top part of the given height
+// and a bottom part with the rest, leaving a margin in between.
+func (a presetArea) splitHorizontal(topHeight uint16) (presetArea, presetArea) {
+	top, bottom := a, a
+	top.height = topHeight
+	bottom.y = a.y + topHeight + a.margin
+	bottom.height = a.height - topHeight - a.margin
+	return top, bottom
+}
+
+// splitVertical cuts the area into a left part of the given width
+// and a right part with the rest, leaving a margin in between.
+func (a presetArea) splitVertical(leftWidth uint16) (presetArea, presetArea) {
+	left, right := a, a
+	left.width = leftWidth
+	right.x = a.x + leftWidth + a.margin
+	right.width = a.width - leftWidth - a.margin
+	return left, right
+}
+
+func (a presetArea) region(name string, sources Sources) *Region {
+	return &Region{
+		Name: name,
+		Prop: &RegionProp{
+			XPos:                 uint16Ptr(a.x),
+			YPos:                 uint16Ptr(a.y),
+			Width:                uint16Ptr(a.width),
+			Height:               uint16Ptr(a.height),
+			VideoSources:         sources.Include,
+			VideoSourcesExcluded: sources.Exclude,
+		},
+	}
+}
+
+// addPresetRegions adds the regions with validation on,
+// so a preset never hands out a layout Twilio would reject.
+func (l *VideoLayout) addPresetRegions(regs ...*Region) error {
+	return l.addRegion(regs[0], true, regs[1:]...)
+}
+
+func uint16Ptr(v uint16) *uint16 {
+	return &v
+}
+
+func int16Ptr(v int16) *int16 {
+	return &v
+}
+
+func stringPtr(v string) *string {
+	return &v
+}
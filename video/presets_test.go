@@ -0,0 +1,149 @@
+package video
+
+import "testing"
+
+var presetResolutions = []Resolution{HD(), PAL(), VGA(), CIF()}
+
+func TestPresetsFitResolutions(t *testing.T) {
+	speaker := Sources{Include: []string{"teacher*"}}
+	for _, res := range presetResolutions {
+		builders := map[string]func() (*VideoLayout, error){
+			"grid": func() (*VideoLayout, error) {
+				return NewGridLayout(res, 7, AllSources, nil)
+			},
+			"speaker": func() (*VideoLayout, error) {
+				return NewSpeakerLayout(res, speaker, AllSources, &SpeakerOptions{
+					PresetOptions: PresetOptions{Margin: 8},
+				})
+			},
+			"pip": func() (*VideoLayout, error) {
+				return NewPictureInPictureLayout(res, AllSources, speaker, &PictureInPictureOptions{
+					PresetOptions: PresetOptions{Margin: 4},
+					Corner:        CornerTopLeft,
+				})
+			},
+			"side-by-side": func() (*VideoLayout, error) {
+				return NewSideBySideLayout(res, speaker, AllSources, nil)
+			},
+			"presenter": func() (*VideoLayout, error) {
+				return NewPresenterLayout(res, Sources{Include: []string{"screen"}}, speaker, nil)
+			},
+		}
+
+		for name, build := range builders {
+			l, err := build()
+			if err != nil {
+				t.Fatalf("%s %s: %v", name, res, err)
+			}
+			for _, r := range l.GetRegions() {
+				p := r.Prop
+				if uint32(*p.XPos)+uint32(*p.Width) > uint32(l.Resolution.Width) ||
+					uint32(*p.YPos)+uint32(*p.Height) > uint32(l.Resolution.Height) {
+					t.Errorf("%s %s: region %q overflows the composition", name, res, r.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestPresetsRejectInvalidRegions(t *testing.T) {
+	if _, err := NewSideBySideLayout(Resolution{Width: 16, Height: 16}, AllSources, AllSources, nil); err == nil {
+		t.Error("expected error for regions narrower than 16 pixels")
+	}
+	if _, err := NewGridLayout(VGA(), 4, AllSources, &PresetOptions{Margin: 240}); err == nil {
+		t.Error("expected error for a margin larger than the composition")
+	}
+	if _, err := NewGridLayout(VGA(), 4, Sources{}, nil); err == nil {
+		t.Error("expected error for a region without video sources")
+	}
+}
+
+func TestGridLayoutCells(t *testing.T) {
+	tests := []struct {
+		n          int
+		cols, rows uint16
+	}{
+		{1, 1, 1},
+		{2, 2, 1},
+		{4, 2, 2},
+		{5, 3, 2},
+		{10, 4, 3},
+	}
+	for _, tt := range tests {
+		l, err := NewGridLayout(VGA(), tt.n, AllSources, &PresetOptions{Margin: 10})
+		if err != nil {
+			t.Fatalf("grid of %d: %v", tt.n, err)
+		}
+		p := l.GetRegions()[0].Prop
+		if *p.MaxColumns != tt.cols || *p.MaxRows != tt.rows {
+			t.Errorf("grid of %d: got %dx%d, want %dx%d", tt.n, *p.MaxColumns, *p.MaxRows, tt.cols, tt.rows)
+		}
+		if *p.XPos != 10 || *p.Width != 620 || *p.Height != 460 {
+			t.Errorf("grid of %d: margin not applied: %+v", tt.n, p)
+		}
+	}
+}
+
+func TestSpeakerLayoutExcludesSpeakerFromStrip(t *testing.T) {
+	l, err := NewSpeakerLayout(HD(), Sources{Include: []string{"teacher"}}, AllSources, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strip := l.GetRegions()[1]
+	if len(strip.Prop.VideoSourcesExcluded) != 1 || strip.Prop.VideoSourcesExcluded[0] != "teacher" {
+		t.Errorf("strip excludes %v, want [teacher]", strip.Prop.VideoSourcesExcluded)
+	}
+}
+
+func TestSpeakerLayoutKeepsWildcardsInStrip(t *testing.T) {
+	l, err := NewSpeakerLayout(HD(), AllSources, AllSources, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if excluded := l.GetRegions()[1].Prop.VideoSourcesExcluded; len(excluded) != 0 {
+		t.Errorf("strip excludes %v, want nothing", excluded)
+	}
+
+	l, err = NewSpeakerLayout(HD(), Sources{Include: []string{"teacher", "guest*"}}, AllSources, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if excluded := l.GetRegions()[1].Prop.VideoSourcesExcluded; len(excluded) != 1 || excluded[0] != "teacher" {
+		t.Errorf("strip excludes %v, want [teacher]", excluded)
+	}
+}
+
+func TestPictureInPictureCorner(t *testing.T) {
+	l, err := NewPictureInPictureLayout(VGA(), AllSources, AllSources, &PictureInPictureOptions{
+		PresetOptions: PresetOptions{Margin: 16},
+		Corner:        CornerBottomRight,
+		InsetWidth:    160,
+		InsetHeight:   120,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inset := l.GetRegions()[1].Prop
+	// The main region starts at the margin, and the inset keeps another margin from its edges.
+	if *inset.XPos != 640-16-16-160 || *inset.YPos != 480-16-16-120 {
+		t.Errorf("inset at %d,%d", *inset.XPos, *inset.YPos)
+	}
+	if *inset.ZPos <= *l.GetRegions()[0].Prop.ZPos {
+		t.Error("inset must be stacked on top of the main region")
+	}
+}
+
+func TestPictureInPictureDefaultCorner(t *testing.T) {
+	// Options setting only the size keep the inset at the bottom right.
+	l, err := NewPictureInPictureLayout(VGA(), AllSources, AllSources, &PictureInPictureOptions{
+		InsetWidth:  160,
+		InsetHeight: 120,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inset := l.GetRegions()[1].Prop
+	if *inset.XPos != 640-160 || *inset.YPos != 480-120 {
+		t.Errorf("inset at %d,%d", *inset.XPos, *inset.YPos)
+	}
+}
@@ -0,0 +1,256 @@
+// Package preview draws a video layout as Twilio would place its regions,
+// so a layout can be reviewed before paying for a composition.
+package preview
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+var (
+	background   = color.RGBA{0x20, 0x20, 0x20, 0xFF}
+	labelColor   = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	excludedFill = color.RGBA{0x00, 0x00, 0x00, 0x99}
+
+	// palette colors the regions in layout order.
+	palette = []color.RGBA{
+		{0x1F, 0x77, 0xB4, 0xFF},
+		{0xFF, 0x7F, 0x0E, 0xFF},
+		{0x2C, 0xA0, 0x2C, 0xFF},
+		{0xD6, 0x27, 0x28, 0xFF},
+		{0x94, 0x67, 0xBD, 0xFF},
+		{0x8C, 0x56, 0x4B, 0xFF},
+		{0xE3, 0x77, 0xC2, 0xFF},
+		{0x17, 0xBE, 0xCF, 0xFF},
+	}
+)
+
+const (
+	regionAlpha = 0x59 // Around 35% so overlapping regions stay visible.
+	labelMargin = 4
+)
+
+// regionView is a region ready to be drawn.
+type regionView struct {
+	name     string
+	z        int16
+	color    color.RGBA
+	bounds   video.Rect
+	cells    []video.Rect
+	excluded map[int]bool
+	labels   []string
+}
+
+// views returns the layout's resolution and regions from the bottom to the top of the stack.
+// Regions with the same z_pos keep the layout order.
+// A layout without resolution, like one from video.NewFrom, is drawn at the default resolution.
+func views(l *video.VideoLayout) (video.Resolution, []regionView, error) {
+	if l == nil {
+		return video.Resolution{}, nil, errors.New("Error, video layout must not be nil.")
+	}
+	res := l.GetResolution()
+	if res.IsZero() {
+		res = video.DefaultResolution()
+	}
+
+	var vs []regionView
+	for i, r := range l.GetRegions() {
+		if r == nil {
+			return video.Resolution{}, nil, errors.New("Error, the region is nil.")
+		}
+
+		v := regionView{
+			name:     r.Name,
+			z:        r.Z(),
+			color:    palette[i%len(palette)],
+			bounds:   r.Bounds(res),
+			excluded: map[int]bool{},
+			labels:   []string{fmt.Sprintf("%s z=%d", r.Name, r.Z())},
+		}
+
+		columns, rows := 1, 1
+		if r.Prop != nil {
+			if r.Prop.MaxColumns != nil {
+				columns = int(*r.Prop.MaxColumns)
+			}
+			if r.Prop.MaxRows != nil {
+				rows = int(*r.Prop.MaxRows)
+			}
+			for _, c := range r.Prop.CellsExcluded {
+				v.excluded[int(c)] = true
+			}
+			if len(r.Prop.VideoSources) > 0 {
+				v.labels = append(v.labels, "src: "+strings.Join(r.Prop.VideoSources, ","))
+			}
+			if len(r.Prop.VideoSourcesExcluded) > 0 {
+				v.labels = append(v.labels, "excl: "+strings.Join(r.Prop.VideoSourcesExcluded, ","))
+			}
+		}
+		v.cells = v.bounds.Cells(columns, rows)
+		vs = append(vs, v)
+	}
+
+	sort.SliceStable(vs, func(i, j int) bool {
+		return vs[i].z < vs[j].z
+	})
+	return res, vs, nil
+}
+
+// SVG writes the layout as an SVG document.
+func SVG(w io.Writer, l *video.VideoLayout) error {
+	res, vs, err := views(l)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		res.Width, res.Height, res.Width, res.Height,
+	)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`+"\n", res.Width, res.Height, hex(background))
+	for _, v := range vs {
+		fmt.Fprintf(&b, `<g id="region-%s" data-z="%d">`+"\n", escape(v.name), v.z)
+		fmt.Fprintf(&b,
+			`<rect x="%d" y="%d" width="%d" height="%d" fill="%s" fill-opacity="0.35" stroke="%s" stroke-width="2"/>`+"\n",
+			v.bounds.X, v.bounds.Y, v.bounds.Width, v.bounds.Height, hex(v.color), hex(v.color),
+		)
+		for i, c := range v.cells {
+			if v.excluded[i] {
+				fmt.Fprintf(&b,
+					`<rect class="excluded" x="%d" y="%d" width="%d" height="%d" fill="#000000" fill-opacity="0.6"/>`+"\n",
+					c.X, c.Y, c.Width, c.Height,
+				)
+				fmt.Fprintf(&b,
+					`<path d="M%d %dL%d %dM%d %dL%d %d" stroke="%s" stroke-width="1"/>`+"\n",
+					c.X, c.Y, c.X+c.Width, c.Y+c.Height, c.X+c.Width, c.Y, c.X, c.Y+c.Height, hex(v.color),
+				)
+			}
+			fmt.Fprintf(&b,
+				`<rect class="cell" x="%d" y="%d" width="%d" height="%d" fill="none" stroke="%s" stroke-width="1" stroke-dasharray="4 2"/>`+"\n",
+				c.X, c.Y, c.Width, c.Height, hex(v.color),
+			)
+		}
+		for i, label := range v.labels {
+			fmt.Fprintf(&b,
+				`<text x="%d" y="%d" font-family="monospace" font-size="12" fill="%s">%s</text>`+"\n",
+				v.bounds.X+labelMargin, v.bounds.Y+labelMargin+12*(i+1), hex(labelColor), escape(label),
+			)
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// Image draws the layout at its resolution.
+func Image(l *video.VideoLayout) (*image.RGBA, error) {
+	res, vs, err := views(l)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, int(res.Width), int(res.Height)))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	scale := int(res.Width) / 640
+	if scale < 1 {
+		scale = 1
+	}
+	for _, v := range vs {
+		r := rect(v.bounds)
+		fill := v.color
+		fill.A = regionAlpha
+		blendRect(img, r, fill)
+		for i, c := range v.cells {
+			cr := rect(c)
+			if v.excluded[i] {
+				blendRect(img, cr, excludedFill)
+				drawCross(img, cr, v.color)
+			}
+			strokeRect(img, cr, 1, v.color)
+		}
+		strokeRect(img, r, 2, v.color)
+		drawText(img, r.Min.X+labelMargin, r.Min.Y+labelMargin, scale, labelColor, v.labels...)
+	}
+	return img, nil
+}
+
+// PNG writes the layout as a PNG image.
+func PNG(w io.Writer, l *video.VideoLayout) error {
+	img, err := Image(l)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+func rect(r video.Rect) image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func blendRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	// image.Uniform expects premultiplied alpha.
+	pre := color.RGBA{
+		R: uint8(uint16(c.R) * uint16(c.A) / 0xFF),
+		G: uint8(uint16(c.G) * uint16(c.A) / 0xFF),
+		B: uint8(uint16(c.B) * uint16(c.A) / 0xFF),
+		A: c.A,
+	}
+	draw.Draw(img, r.Intersect(img.Bounds()), image.NewUniform(pre), image.Point{}, draw.Over)
+}
+
+func strokeRect(img *image.RGBA, r image.Rectangle, width int, c color.RGBA) {
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width), c)
+	fillRect(img, image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+// drawCross draws both diagonals of the rectangle.
+func drawCross(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	w, h := r.Dx(), r.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+	steps := w
+	if h > steps {
+		steps = h
+	}
+	for i := 0; i <= steps; i++ {
+		x, y := r.Min.X+i*(w-1)/steps, r.Min.Y+i*(h-1)/steps
+		if (image.Point{X: x, Y: y}).In(img.Bounds()) {
+			img.SetRGBA(x, y, c)
+		}
+		x = r.Max.X - 1 - i*(w-1)/steps
+		if (image.Point{X: x, Y: y}).In(img.Bounds()) {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
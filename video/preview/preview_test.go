@@ -0,0 +1,137 @@
+package preview
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func testLayouts(t *testing.T) map[string]*video.VideoLayout {
+	speaker, err := video.NewSpeakerLayout(
+		video.VGA(),
+		video.Sources{Include: []string{"teacher"}},
+		video.AllSources,
+		&video.SpeakerOptions{PresetOptions: video.PresetOptions{Margin: 8}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pip, err := video.NewVideoLayout(video.CIF())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pip.NewRegion("main").
+		Grid(3, 2).
+		ExcludeCells(1, 4).
+		Sources("student*").
+		Add(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pip.NewRegion("inset").
+		Position(220, 150).
+		Size(80, 60).
+		Z(10).
+		Sources("teacher").
+		Add(); err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]*video.VideoLayout{
+		"speaker":  speaker,
+		"excluded": pip,
+	}
+}
+
+func TestSVGGolden(t *testing.T) {
+	for name, l := range testLayouts(t) {
+		var buf bytes.Buffer
+		if err := SVG(&buf, l); err != nil {
+			t.Fatal(err)
+		}
+
+		golden := filepath.Join("testdata", name+".svg")
+		if *update {
+			if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%s: SVG differs from %s, run with -update after checking the change", name, golden)
+		}
+	}
+}
+
+func TestPNGGolden(t *testing.T) {
+	for name, l := range testLayouts(t) {
+		var buf bytes.Buffer
+		if err := PNG(&buf, l); err != nil {
+			t.Fatal(err)
+		}
+
+		golden := filepath.Join("testdata", name+".png")
+		if *update {
+			if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		f, err := os.Open(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Compare pixels rather than bytes, the encoder's compression may change between Go versions.
+		if !samePixels(got, want) {
+			t.Errorf("%s: PNG differs from %s, run with -update after checking the change", name, golden)
+		}
+	}
+}
+
+func TestZOrder(t *testing.T) {
+	_, vs, err := views(testLayouts(t)["excluded"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vs[len(vs)-1].name != "inset" {
+		t.Errorf("inset with the highest z_pos must be drawn last, got %q", vs[len(vs)-1].name)
+	}
+	if len(vs[0].cells) != 6 || !vs[0].excluded[1] || !vs[0].excluded[4] {
+		t.Errorf("main must have 6 cells with 1 and 4 excluded, got %d cells, %v", len(vs[0].cells), vs[0].excluded)
+	}
+}
+
+func samePixels(a, b image.Image) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+	for y := a.Bounds().Min.Y; y < a.Bounds().Max.Y; y++ {
+		for x := a.Bounds().Min.X; x < a.Bounds().Max.X; x++ {
+			r1, g1, b1, a1 := a.At(x, y).RGBA()
+			r2, g2, b2, a2 := b.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+)
+
+// maxBoxSize bounds the boxes read in memory, moov of a composition is far smaller.
+const maxBoxSize = 64 << 20
+
+type box struct {
+	typ   string
+	start int64 // Offset of the payload.
+	size  int64 // Payload size.
+}
+
+// readBox reads the header of the box at offset, within a parent ending at end.
+func readBox(r io.ReadSeeker, offset, end int64) (box, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return box{}, err
+	}
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return box{}, io.ErrUnexpectedEOF
+	}
+	b := box{typ: string(hdr[4:]), start: offset + 8}
+	size := int64(binary.BigEndian.Uint32(hdr[:4]))
+	switch size {
+	case 0: // Up to the end of the file.
+		size = end - offset
+	case 1:
+		var large [8]byte
+		if _, err := io.ReadFull(r, large[:]); err != nil {
+			return box{}, io.ErrUnexpectedEOF
+		}
+		size = int64(binary.BigEndian.Uint64(large[:]))
+		b.start += 8
+	}
+	b.size = size - (b.start - offset)
+	if b.size < 0 {
+		return box{}, fmt.Errorf("Error, box %q at %d has an invalid size.", b.typ, offset)
+	}
+	return b, nil
+}
+
+func probeMP4(r io.ReadSeeker, fileSize int64) (*Result, error) {
+	res := &Result{Format: composition.MediaFormatMP4}
+	var moov []byte
+	for offset := int64(0); offset < fileSize; {
+		b, err := readBox(r, offset, fileSize)
+		if err == io.ErrUnexpectedEOF {
+			res.Truncated = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b.start+b.size > fileSize {
+			res.Truncated = true
+		}
+		if b.typ == "moov" && !res.Truncated {
+			if b.size > maxBoxSize {
+				return nil, errors.New("Error, the moov box is too large.")
+			}
+			moov = make([]byte, b.size)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return nil, err
+			}
+		}
+		offset = b.start + b.size
+	}
+	if moov == nil {
+		if res.Truncated {
+			return res, nil
+		}
+		return nil, errors.New("Error, the file has no moov box.")
+	}
+
+	err := walkBoxes(moov, func(typ string, payload []byte) error {
+		switch typ {
+		case "mvhd":
+			d, err := parseMvhd(payload)
+			res.Duration = d
+			return err
+		case "trak":
+			return parseTrak(payload, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// walkBoxes calls fn for every box of data, boxes in memory are never larger than data.
+func walkBoxes(data []byte, fn func(typ string, payload []byte) error) error {
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return errors.New("Error, a box header is cut.")
+		}
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		typ := string(data[4:8])
+		hdr := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return errors.New("Error, a box header is cut.")
+			}
+			size, hdr = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		if size < hdr || size > uint64(len(data)) {
+			return fmt.Errorf("Error, box %q has an invalid size.", typ)
+		}
+		if err := fn(typ, data[hdr:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// parseMvhd reads the movie's duration, in its timescale,
+// after the version and flags and the creation and modification times.
+func parseMvhd(p []byte) (time.Duration, error) {
+	if len(p) < 4 {
+		return 0, errors.New("Error, the mvhd box is cut.")
+	}
+	var timescale, duration uint64
+	if p[0] == 1 {
+		if len(p) < 32 {
+			return 0, errors.New("Error, the mvhd box is cut.")
+		}
+		timescale = uint64(binary.BigEndian.Uint32(p[20:24]))
+		duration = binary.BigEndian.Uint64(p[24:32])
+	} else {
+		if len(p) < 20 {
+			return 0, errors.New("Error, the mvhd box is cut.")
+		}
+		timescale = uint64(binary.BigEndian.Uint32(p[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(p[16:20]))
+	}
+	if timescale == 0 {
+		return 0, errors.New("Error, the mvhd box has no timescale.")
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+func parseTrak(trak []byte, res *Result) error {
+	var handler string
+	var width, height uint32
+	err := walkBoxes(trak, func(typ string, p []byte) error {
+		switch typ {
+		case "tkhd":
+			// The width and height, fixed point 16.16, end the box.
+			if len(p) < 8 {
+				return errors.New("Error, the tkhd box is cut.")
+			}
+			width = binary.BigEndian.Uint32(p[len(p)-8:len(p)-4]) >> 16
+			height = binary.BigEndian.Uint32(p[len(p)-4:]) >> 16
+		case "mdia":
+			return walkBoxes(p, func(typ string, p []byte) error {
+				// The handler type follows the version, flags and pre_defined.
+				if typ == "hdlr" && len(p) >= 12 {
+					handler = string(p[8:12])
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch handler {
+	case "vide":
+		res.HasVideo = true
+		res.Resolution = video.Resolution{Width: uint16(width), Height: uint16(height)}
+	case "soun":
+		res.HasAudio = true
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package probe reads the metadata of downloaded compositions, MP4 and WebM files,
+// to check them against what the API says about them.
+//
+// MP4 files are read from their moov box, its mvhd and the tkhd and hdlr of each trak,
+// WebM files with the matroska package. The media itself is skipped.
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/matroska"
+)
+
+// Result is what the file tells about itself.
+type Result struct {
+	Format     composition.MediaFormat
+	Duration   time.Duration
+	Resolution video.Resolution
+	HasVideo   bool
+	HasAudio   bool
+
+	// Size is the length of the file in bytes.
+	Size int64
+
+	// Bitrate is the average bit rate of the file in kbps.
+	Bitrate int
+
+	// Truncated is set when the file ends inside a box or an element.
+	Truncated bool
+}
+
+// ProbeFile probes the file at path.
+func ProbeFile(path string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Probe(f)
+}
+
+// Probe reads the metadata of an MP4 or a WebM file. The format is told by the content,
+// not by the name. A file cut short is reported by Result.Truncated.
+func Probe(r io.ReadSeeker) (*Result, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var magic [8]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, errors.New("Error, the file is too short to be a media file.")
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var res *Result
+	switch {
+	case binary.BigEndian.Uint32(magic[:4]) == 0x1A45DFA3:
+		res, err = probeWebM(r)
+	case string(magic[4:8]) == "ftyp":
+		res, err = probeMP4(r, size)
+	default:
+		return nil, errors.New("Error, the file is neither MP4 nor WebM.")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res.Size = size
+	if res.Duration > 0 {
+		res.Bitrate = int(float64(size*8) / res.Duration.Seconds() / 1000)
+	}
+	return res, nil
+}
+
+func probeWebM(r io.Reader) (*Result, error) {
+	info, err := matroska.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if info.DocType != "webm" {
+		return nil, fmt.Errorf("Error, expected a webm document, got %q.", info.DocType)
+	}
+
+	res := &Result{
+		Format:    composition.MediaFormatWebM,
+		Duration:  info.MediaDuration(),
+		Truncated: info.Truncated,
+	}
+	for _, t := range info.Tracks {
+		switch t.Type {
+		case matroska.TrackTypeVideo:
+			res.HasVideo = true
+			res.Resolution = video.Resolution{Width: uint16(t.Width), Height: uint16(t.Height)}
+		case matroska.TrackTypeAudio:
+			res.HasAudio = true
+		}
+	}
+	return res, nil
+}
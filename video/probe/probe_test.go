@@ -0,0 +1,162 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+)
+
+func mp4Box(typ string, children ...[]byte) []byte {
+	data := bytes.Join(children, nil)
+	b := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(b, uint32(8+len(data)))
+	copy(b[4:], typ)
+	return append(b, data...)
+}
+
+func u32(v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return b[:]
+}
+
+func tkhd(width, height uint32) []byte {
+	p := make([]byte, 84)
+	copy(p[76:], u32(width<<16))
+	copy(p[80:], u32(height<<16))
+	return mp4Box("tkhd", p)
+}
+
+func hdlr(handler string) []byte {
+	p := make([]byte, 24)
+	copy(p[8:], handler)
+	return mp4Box("hdlr", p)
+}
+
+func mp4File(mdatSize int) []byte {
+	mvhd := bytes.Join([][]byte{u32(0), u32(0), u32(0), u32(1000), u32(10000), make([]byte, 80)}, nil)
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("isom"), u32(512), []byte("isomiso2avc1mp41")),
+		mp4Box("moov",
+			mp4Box("mvhd", mvhd),
+			mp4Box("trak", tkhd(640, 480), mp4Box("mdia", hdlr("vide"))),
+			mp4Box("trak", tkhd(0, 0), mp4Box("mdia", hdlr("soun"))),
+		),
+		mp4Box("mdat", make([]byte, mdatSize)),
+	}, nil)
+}
+
+func ebml(id []byte, children ...[]byte) []byte {
+	data := bytes.Join(children, nil)
+	return append(append(append([]byte{}, id...), 0x80|byte(len(data))), data...)
+}
+
+func webmFile() []byte {
+	var duration [8]byte
+	binary.BigEndian.PutUint64(duration[:], math.Float64bits(4000))
+	return bytes.Join([][]byte{
+		ebml([]byte{0x1A, 0x45, 0xDF, 0xA3}, ebml([]byte{0x42, 0x82}, []byte("webm"))),
+		ebml([]byte{0x18, 0x53, 0x80, 0x67},
+			ebml([]byte{0x15, 0x49, 0xA9, 0x66}, ebml([]byte{0x44, 0x89}, duration[:])),
+			ebml([]byte{0x16, 0x54, 0xAE, 0x6B},
+				ebml([]byte{0xAE},
+					ebml([]byte{0x83}, []byte{1}),
+					ebml([]byte{0x86}, []byte("V_VP8")),
+					ebml([]byte{0xE0}, ebml([]byte{0xB0}, []byte{0x01, 0x40}), ebml([]byte{0xBA}, []byte{0xF0})),
+				),
+			),
+		),
+	}, nil)
+}
+
+func TestProbeMP4(t *testing.T) {
+	file := mp4File(1250000 - 300)
+	res, err := Probe(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Format != composition.MediaFormatMP4 || res.Truncated || !res.HasVideo || !res.HasAudio {
+		t.Errorf("got %+v", res)
+	}
+	if res.Duration.Seconds() != 10 || res.Resolution != video.VGA() {
+		t.Errorf("got duration %v and resolution %v", res.Duration, res.Resolution)
+	}
+	if res.Size != int64(len(file)) || res.Bitrate != len(file)*8/10/1000 {
+		t.Errorf("got size %d and bitrate %d", res.Size, res.Bitrate)
+	}
+}
+
+func TestProbeMP4Truncated(t *testing.T) {
+	file := mp4File(1000)
+	res, err := Probe(bytes.NewReader(file[:len(file)-100]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Truncated || res.Duration.Seconds() != 10 {
+		t.Errorf("got %+v", res)
+	}
+}
+
+func TestProbeWebM(t *testing.T) {
+	res, err := Probe(bytes.NewReader(webmFile()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Format != composition.MediaFormatWebM || res.Duration.Seconds() != 4 || res.Truncated {
+		t.Errorf("got %+v", res)
+	}
+	if !res.HasVideo || res.HasAudio || res.Resolution != (video.Resolution{Width: 320, Height: 240}) {
+		t.Errorf("got video %v, audio %v, resolution %v", res.HasVideo, res.HasAudio, res.Resolution)
+	}
+}
+
+func TestProbeUnknown(t *testing.T) {
+	if _, err := Probe(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00WAVEfmt "))); err == nil {
+		t.Error("probed a wav file")
+	}
+}
+
+func TestVerifyComposition(t *testing.T) {
+	file := mp4File(1250000 - 300)
+	res, err := Probe(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout, err := video.NewGridLayout(video.VGA(), 2, video.AllSources, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &composition.Composition{
+		Sid:         "CJ1",
+		Format:      composition.MediaFormatMP4,
+		Resolution:  video.VGA(),
+		Duration:    10,
+		Bitrate:     1000,
+		Size:        len(file),
+		VideoLayout: layout,
+	}
+	if r := VerifyComposition(res, c); !r.OK() {
+		t.Errorf("got mismatches %v", r.Mismatches)
+	}
+
+	c.Format = composition.MediaFormatWebM
+	c.Resolution = video.HD()
+	c.Duration = 30
+	c.Bitrate = 500
+	c.Size = len(file) + 1
+	r := VerifyComposition(res, c)
+	want := []string{"size", "format", "resolution", "duration", "bitrate"}
+	if len(r.Mismatches) != len(want) {
+		t.Fatalf("got mismatches %v, want %v", r.Mismatches, want)
+	}
+	for i, m := range r.Mismatches {
+		if m.Property != want[i] {
+			t.Errorf("mismatch %d: got %s, want %s", i, m.Property, want[i])
+		}
+	}
+}
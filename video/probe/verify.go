@@ -0,0 +1,79 @@
+package probe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+)
+
+// DurationTolerance is how far the duration of a file may be from its metadata.
+// Twilio rounds composition durations to the second.
+const DurationTolerance = 1500 * time.Millisecond
+
+// BitrateTolerance is the fraction of the metadata's bit rate the file's may differ by,
+// the file's is computed from its size and includes the container overhead.
+const BitrateTolerance = 0.2
+
+// Mismatch is a property of a file that differs from its metadata.
+type Mismatch struct {
+	Property string `json:"property"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: expected %s, got %s", m.Property, m.Expected, m.Actual)
+}
+
+// Report lists how a downloaded composition differs from its metadata.
+type Report struct {
+	CompositionSid string     `json:"composition_sid"`
+	Result         *Result    `json:"result"`
+	Mismatches     []Mismatch `json:"mismatches"`
+}
+
+// OK reports whether the file matches its metadata and can be kept.
+func (r *Report) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// VerifyComposition compares a downloaded composition with its metadata:
+// its format, resolution, duration and bit rate, and that it is complete.
+// The resolution is only checked for compositions with a video layout.
+func VerifyComposition(res *Result, c *composition.Composition) *Report {
+	r := &Report{CompositionSid: c.Sid, Result: res, Mismatches: []Mismatch{}}
+	add := func(property string, expected, actual interface{}) {
+		r.Mismatches = append(r.Mismatches, Mismatch{
+			Property: property,
+			Expected: fmt.Sprint(expected),
+			Actual:   fmt.Sprint(actual),
+		})
+	}
+
+	if res.Truncated {
+		add("complete", true, "truncated")
+	}
+	if c.Size > 0 && res.Size != int64(c.Size) {
+		add("size", c.Size, res.Size)
+	}
+	if c.Format != "" && res.Format != c.Format {
+		add("format", c.Format, res.Format)
+	}
+	if c.VideoLayout != nil && len(c.VideoLayout.GetRegions()) > 0 {
+		if !res.HasVideo {
+			add("video", true, false)
+		} else if !c.Resolution.IsZero() && res.Resolution != c.Resolution {
+			add("resolution", c.Resolution, res.Resolution)
+		}
+	}
+	if diff := res.Duration - c.GetDuration(); diff > DurationTolerance || diff < -DurationTolerance {
+		add("duration", c.GetDuration(), res.Duration)
+	}
+	if c.Bitrate > 0 && res.Bitrate > 0 {
+		if diff := float64(res.Bitrate-c.Bitrate) / float64(c.Bitrate); diff > BitrateTolerance || diff < -BitrateTolerance {
+			add("bitrate", c.Bitrate, res.Bitrate)
+		}
+	}
+	return r
+}
@@ -4,20 +4,21 @@ import "time"
 
 type RecordingInstance struct {
 	AccountSid      string    `json:"account_sid"`
-	Status          string    `json:"status"`
+	Status          Status    `json:"status"`
 	DateCreated     time.Time `json:"date_created"`
 	Sid             string    `json:"sid"`
 	SourceSid       string    `json:"source_sid"`
 	Size            int       `json:"size"`
 	URL             string    `json:"url"`
-	Type            string    `json:"type"`
+	Type            Type      `json:"type"`
 	Duration        int       `json:"duration"`
-	ContainerFormat string    `json:"container_format"`
-	Codec           string    `json:"codec"`
+	ContainerFormat Container `json:"container_format"`
+	Codec           Codec     `json:"codec"`
 	TrackName       string    `json:"track_name"`
 	Offset          int       `json:"offset"`
 	GroupingSids    struct {
-		RoomSid string `json:"room_sid"`
+		RoomSid        string `json:"room_sid"`
+		ParticipantSid string `json:"participant_sid"`
 	} `json:"grouping_sids"`
 	MediaExternalLocation string `json:"media_external_location"`
 	Links                 struct {
@@ -38,6 +39,21 @@ type RecordingList struct {
 	} `json:"meta"`
 }
 
+// GetDuration returns the duration of the recorded media.
+func (r *RecordingInstance) GetDuration() time.Duration {
+	return time.Duration(r.Duration) * time.Second
+}
+
+type Status string
+
+const (
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+	StatusDeleted    Status = "deleted"
+	StatusFailed     Status = "failed"
+	StatusAbsent     Status = "absent"
+)
+
 type Media struct {
 	RedirectTo string `json:"redirect_to"`
 }
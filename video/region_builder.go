@@ -0,0 +1,225 @@
+package video
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReusePolicy defines how a region's grid cells are reused for placement purposes.
+// See RegionProp.Reuse.
+type ReusePolicy string
+
+const (
+	ReusePolicyShowOldest ReusePolicy = ReuseShowOldest
+	ReusePolicyShowNewest ReusePolicy = ReuseShowNewest
+	ReusePolicyNone       ReusePolicy = ReuseNone
+)
+
+// RegionBuilder builds a region of a video layout step by step.
+// Every step is checked as it is called, and the violations are collected
+// instead of stopping the chain, so they can be read at once from Err, Build or Add.
+// Whether the region fits the layout's resolution is checked once by Build and Add,
+// when both its position and size are known.
+type RegionBuilder struct {
+	layout *VideoLayout
+	region *Region
+	errs   ValidationErrors
+}
+
+// NewRegion starts building a region named name on the layout.
+// The region is not part of the layout until Add is called.
+func (l *VideoLayout) NewRegion(name string) *RegionBuilder {
+	b := &RegionBuilder{
+		layout: l,
+		region: &Region{Name: name, Prop: &RegionProp{}},
+	}
+	if name == "" {
+		b.fail(errors.New("name must not be empty"))
+	}
+	return b
+}
+
+// Position sets the region's upper left corner in pixels.
+func (b *RegionBuilder) Position(x, y uint16) *RegionBuilder {
+	b.region.Prop.XPos = &x
+	b.region.Prop.YPos = &y
+	return b
+}
+
+// Size sets the region's width and height in pixels.
+func (b *RegionBuilder) Size(width, height uint16) *RegionBuilder {
+	if width < XPosDefaultInPix || height < YPosDefaultInPix {
+		b.fail(fmt.Errorf(
+			"size %dx%d is smaller than %dx%d",
+			width, height, XPosDefaultInPix, YPosDefaultInPix,
+		))
+	}
+	b.region.Prop.Width = &width
+	b.region.Prop.Height = &height
+	return b
+}
+
+// Z sets the region's stacking order, regions with higher values are on top.
+func (b *RegionBuilder) Z(z int16) *RegionBuilder {
+	if z < zPosLowerRange || z > zPosUpperRange {
+		b.fail(fmt.Errorf("z_pos %d is out of range [%d, %d]", z, zPosLowerRange, zPosUpperRange))
+	}
+	b.region.Prop.ZPos = &z
+	return b
+}
+
+// Grid sets the maximum number of columns and rows of the region's placement grid.
+func (b *RegionBuilder) Grid(columns, rows uint16) *RegionBuilder {
+	if columns < 1 || columns > 1000 {
+		b.fail(fmt.Errorf("max_columns %d is out of range [1, 1000]", columns))
+	}
+	if rows < 1 || rows > 1000 {
+		b.fail(fmt.Errorf("max_rows %d is out of range [1, 1000]", rows))
+	}
+	b.region.Prop.MaxColumns = &columns
+	b.region.Prop.MaxRows = &rows
+	return b
+}
+
+// ExcludeCells adds grid cells where no video source can be placed.
+func (b *RegionBuilder) ExcludeCells(cells ...uint32) *RegionBuilder {
+	for _, c := range cells {
+		if c > 999999 {
+			b.fail(fmt.Errorf("cells_excluded %d is out of range [0, 999999]", c))
+		}
+	}
+	b.region.Prop.CellsExcluded = append(b.region.Prop.CellsExcluded, cells...)
+	return b
+}
+
+// Reuse sets how the region's grid cells are reused.
+func (b *RegionBuilder) Reuse(policy ReusePolicy) *RegionBuilder {
+	switch policy {
+	case ReusePolicyShowOldest, ReusePolicyShowNewest, ReusePolicyNone:
+	default:
+		b.fail(fmt.Errorf("reuse %q is not supported", policy))
+	}
+	reuse := string(policy)
+	b.region.Prop.Reuse = &reuse
+	return b
+}
+
+// Sources adds video sources to place in the region.
+func (b *RegionBuilder) Sources(sources ...string) *RegionBuilder {
+	b.region.Prop.VideoSources = append(b.region.Prop.VideoSources, sources...)
+	return b
+}
+
+// ExcludeSources adds video sources to keep out of the region.
+func (b *RegionBuilder) ExcludeSources(sources ...string) *RegionBuilder {
+	b.region.Prop.VideoSourcesExcluded = append(b.region.Prop.VideoSourcesExcluded, sources...)
+	return b
+}
+
+// Err returns the violations collected so far, or nil.
+func (b *RegionBuilder) Err() error {
+	return b.errs.errOrNil()
+}
+
+// Build returns a copy of the region without adding it to the layout,
+// so the builder can go on without changing the returned region.
+func (b *RegionBuilder) Build() (*Region, error) {
+	errs := append(ValidationErrors{}, b.errs...)
+	if len(b.region.Prop.VideoSources) == 0 {
+		errs = append(errs, b.regionError(errors.New("video sources must be specified")))
+	}
+	errs = append(errs, b.checkFit()...)
+	if err := errs.errOrNil(); err != nil {
+		return nil, err
+	}
+	return b.copyRegion(), nil
+}
+
+// Add builds the region and adds it to the layout.
+func (b *RegionBuilder) Add() (*Region, error) {
+	reg, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.layout.addRegion(reg, true); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+func (b *RegionBuilder) fail(err error) {
+	b.errs = append(b.errs, b.regionError(err))
+}
+
+func (b *RegionBuilder) regionError(err error) error {
+	return &RegionError{Region: b.region.Name, Err: err}
+}
+
+// copyRegion returns a deep copy of the region being built.
+func (b *RegionBuilder) copyRegion() *Region {
+	p := *b.region.Prop
+	p.XPos = copyPtr(p.XPos)
+	p.YPos = copyPtr(p.YPos)
+	p.Width = copyPtr(p.Width)
+	p.Height = copyPtr(p.Height)
+	p.MaxColumns = copyPtr(p.MaxColumns)
+	p.MaxRows = copyPtr(p.MaxRows)
+	if p.ZPos != nil {
+		z := *p.ZPos
+		p.ZPos = &z
+	}
+	if p.Reuse != nil {
+		reuse := *p.Reuse
+		p.Reuse = &reuse
+	}
+	p.CellsExcluded = append([]uint32(nil), p.CellsExcluded...)
+	p.VideoSources = append([]string(nil), p.VideoSources...)
+	p.VideoSourcesExcluded = append([]string(nil), p.VideoSourcesExcluded...)
+	return &Region{Name: b.region.Name, Prop: &p}
+}
+
+func copyPtr(v *uint16) *uint16 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// checkFit returns the violations when the region overflows the layout's resolution.
+// A missing width or height counts as 16 pixels like Twilio does.
+// Layouts without a known resolution, such as ones from NewFrom, are not checked.
+func (b *RegionBuilder) checkFit() ValidationErrors {
+	if b.layout.Resolution.IsZero() {
+		return nil
+	}
+
+	p := b.region.Prop
+	x, y := uint32(0), uint32(0)
+	if p.XPos != nil {
+		x = uint32(*p.XPos)
+	}
+	if p.YPos != nil {
+		y = uint32(*p.YPos)
+	}
+	w, h := uint32(XPosDefaultInPix), uint32(YPosDefaultInPix)
+	if p.Width != nil {
+		w = uint32(*p.Width)
+	}
+	if p.Height != nil {
+		h = uint32(*p.Height)
+	}
+
+	var errs ValidationErrors
+	if x+w > uint32(b.layout.Resolution.Width) {
+		errs = append(errs, b.regionError(fmt.Errorf(
+			"x_pos %d + width %d overflows the composition width %d", x, w, b.layout.Resolution.Width,
+		)))
+	}
+	if y+h > uint32(b.layout.Resolution.Height) {
+		errs = append(errs, b.regionError(fmt.Errorf(
+			"y_pos %d + height %d overflows the composition height %d", y, h, b.layout.Resolution.Height,
+		)))
+	}
+	return errs
+}
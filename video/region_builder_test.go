@@ -0,0 +1,123 @@
+package video
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegionBuilderAdd(t *testing.T) {
+	l, err := NewVideoLayout(VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg, err := l.NewRegion("grid").
+		Position(10, 20).
+		Size(320, 240).
+		Z(5).
+		Grid(2, 2).
+		ExcludeCells(3).
+		Reuse(ReusePolicyShowNewest).
+		Sources("student*").
+		ExcludeSources("teacher").
+		Add()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := reg.Prop
+	if *p.XPos != 10 || *p.YPos != 20 || *p.Width != 320 || *p.Height != 240 ||
+		*p.ZPos != 5 || *p.MaxColumns != 2 || *p.MaxRows != 2 ||
+		*p.Reuse != ReuseShowNewest || p.CellsExcluded[0] != 3 ||
+		p.VideoSources[0] != "student*" || p.VideoSourcesExcluded[0] != "teacher" {
+		t.Errorf("unexpected properties: %+v", p)
+	}
+	if len(l.GetRegions()) != 1 {
+		t.Errorf("got %d regions, want 1", len(l.GetRegions()))
+	}
+}
+
+func TestRegionBuilderAccumulatesErrors(t *testing.T) {
+	l, err := NewVideoLayout(VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = l.NewRegion("bad").
+		Position(630, 0).
+		Size(8, 490).
+		Z(100).
+		Grid(0, 1).
+		Reuse("sometimes").
+		Add()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+	// Height overflow, size too small, z_pos, max_columns, reuse and sources.
+	if len(errs) != 6 {
+		t.Errorf("got %d errors, want 6: %v", len(errs), errs)
+	}
+	for _, e := range errs {
+		var regErr *RegionError
+		if !errors.As(e, &regErr) || regErr.Region != "bad" {
+			t.Errorf("error %v does not name the region", e)
+		}
+	}
+	if len(l.GetRegions()) != 0 {
+		t.Error("invalid region must not be added")
+	}
+}
+
+func TestRegionBuilderReportsOverflowOnce(t *testing.T) {
+	l, err := NewVideoLayout(VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = l.NewRegion("wide").
+		Position(630, 0).
+		Size(100, 100).
+		Sources("*").
+		Add()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("got %d errors, want 1: %v", len(errs), errs)
+	}
+}
+
+func TestRegionBuilderBuildIsRepeatable(t *testing.T) {
+	l, err := NewVideoLayout(VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := l.NewRegion("wide").Position(630, 0).Size(100, 100)
+	for i := 0; i < 2; i++ {
+		_, err := b.Build()
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Errorf("build %d: got %v, want the overflow and missing sources", i, err)
+		}
+	}
+	if err := b.Err(); err != nil {
+		t.Errorf("build changed the collected errors: %v", err)
+	}
+
+	reg, err := b.Position(0, 0).Sources("*").Add()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Position(10, 10).Sources("teacher")
+	if *reg.Prop.XPos != 0 || len(reg.Prop.VideoSources) != 1 {
+		t.Errorf("the builder changed the added region: %+v", reg.Prop)
+	}
+	if added := l.GetRegions()[0]; *added.Prop.XPos != 0 {
+		t.Errorf("the builder changed the layout's region: %+v", added.Prop)
+	}
+}
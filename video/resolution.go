@@ -0,0 +1,168 @@
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Resolution is the columns (width) and rows (height) of a composed video in pixels.
+// It is encoded as "{width}x{height}" in JSON and forms, such as "640x480".
+type Resolution struct {
+	Width  uint16
+	Height uint16
+}
+
+const (
+	minResolutionSide = 16
+	maxResolutionSide = 1280
+	maxResolutionArea = 921600
+)
+
+// Presets of common resolutions. They are functions so no package can change them.
+func HD() Resolution  { return Resolution{Width: 1280, Height: 720} }
+func PAL() Resolution { return Resolution{Width: 1024, Height: 576} }
+func VGA() Resolution { return Resolution{Width: 640, Height: 480} }
+func CIF() Resolution { return Resolution{Width: 320, Height: 240} }
+
+// DefaultResolution returns the resolution Twilio uses when none is given.
+func DefaultResolution() Resolution {
+	return VGA()
+}
+
+// ParseResolution parses a "{width}x{height}" string and checks
+// that it is a resolution Twilio accepts.
+func ParseResolution(s string) (Resolution, error) {
+	sep := strings.Split(s, "x")
+	if len(sep) != 2 {
+		return Resolution{}, fmt.Errorf("Error, invalid resolution %q, want {width}x{height}.", s)
+	}
+
+	width, err := strconv.ParseUint(sep[0], 10, 16)
+	if err != nil {
+		return Resolution{}, fmt.Errorf("Error, invalid resolution width %q.", sep[0])
+	}
+	height, err := strconv.ParseUint(sep[1], 10, 16)
+	if err != nil {
+		return Resolution{}, fmt.Errorf("Error, invalid resolution height %q.", sep[1])
+	}
+
+	r := Resolution{Width: uint16(width), Height: uint16(height)}
+	if err := r.Validate(); err != nil {
+		return Resolution{}, err
+	}
+	return r, nil
+}
+
+func (r Resolution) String() string {
+	return fmt.Sprintf("%dx%d", r.Width, r.Height)
+}
+
+func (r Resolution) IsZero() bool {
+	return r.Width == 0 && r.Height == 0
+}
+
+func (r Resolution) Area() uint32 {
+	return uint32(r.Width) * uint32(r.Height)
+}
+
+// Validate checks the resolution against Twilio's restrictions:
+//
+//	16 <= {width} <= 1280
+//	16 <= {height} <= 1280
+//	{width} * {height} <= 921,600
+func (r Resolution) Validate() error {
+	if r.Width < minResolutionSide || r.Width > maxResolutionSide {
+		return fmt.Errorf(
+			"Error, resolution width %d is out of range [%d, %d].",
+			r.Width, minResolutionSide, maxResolutionSide,
+		)
+	}
+	if r.Height < minResolutionSide || r.Height > maxResolutionSide {
+		return fmt.Errorf(
+			"Error, resolution height %d is out of range [%d, %d].",
+			r.Height, minResolutionSide, maxResolutionSide,
+		)
+	}
+	if r.Area() > maxResolutionArea {
+		return fmt.Errorf("Error, resolution area %d exceeds %d.", r.Area(), maxResolutionArea)
+	}
+	return nil
+}
+
+// AspectRatio returns the resolution's aspect ratio in lowest terms, for example 16:9 for HD.
+func (r Resolution) AspectRatio() (uint16, uint16) {
+	d := gcd(r.Width, r.Height)
+	if d == 0 {
+		return 0, 0
+	}
+	return r.Width / d, r.Height / d
+}
+
+// LargestResolution returns the valid resolution with the largest area
+// that has exactly the aspect ratio width:height.
+func LargestResolution(width, height uint16) (Resolution, error) {
+	if width == 0 || height == 0 {
+		return Resolution{}, fmt.Errorf("Error, invalid aspect ratio %d:%d.", width, height)
+	}
+	d := gcd(width, height)
+	w, h := uint32(width/d), uint32(height/d)
+
+	// Scale the reduced ratio as far as the side and area limits allow.
+	k := uint32(maxResolutionSide) / w
+	if byH := uint32(maxResolutionSide) / h; byH < k {
+		k = byH
+	}
+	for k > 0 && k*k*w*h > maxResolutionArea {
+		k--
+	}
+
+	r := Resolution{Width: uint16(k * w), Height: uint16(k * h)}
+	if err := r.Validate(); err != nil {
+		return Resolution{}, fmt.Errorf("Error, no valid resolution with aspect ratio %d:%d.", width, height)
+	}
+	return r, nil
+}
+
+func (r Resolution) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Resolution) UnmarshalText(text []byte) error {
+	parsed, err := ParseResolution(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// MarshalJSON encodes the resolution as "{width}x{height}", and the zero Resolution as "".
+func (r Resolution) MarshalJSON() ([]byte, error) {
+	if r.IsZero() {
+		return json.Marshal("")
+	}
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON decodes a "{width}x{height}" string.
+// An empty string, as Twilio gives for audio only compositions, is the zero Resolution.
+func (r *Resolution) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*r = Resolution{}
+		return nil
+	}
+	return r.UnmarshalText([]byte(s))
+}
+
+func gcd(a, b uint16) uint16 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
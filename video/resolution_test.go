@@ -0,0 +1,102 @@
+package video
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ajg/form"
+)
+
+func TestParseResolution(t *testing.T) {
+	valid := map[string]Resolution{
+		"1280x720": HD(),
+		"640x480":  VGA(),
+		"16x16":    {Width: 16, Height: 16},
+		"720x1280": {Width: 720, Height: 1280},
+	}
+	for s, want := range valid {
+		got, err := ParseResolution(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+		if got != want || got.String() != s {
+			t.Errorf("%s: got %v", s, got)
+		}
+	}
+
+	invalid := []string{"", "640", "640x", "x480", "15x480", "1281x16", "1280x1280", "-1x16", "640x480x1"}
+	for _, s := range invalid {
+		if _, err := ParseResolution(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestResolutionAspectRatio(t *testing.T) {
+	if w, h := HD().AspectRatio(); w != 16 || h != 9 {
+		t.Errorf("HD aspect ratio %d:%d", w, h)
+	}
+	if w, h := VGA().AspectRatio(); w != 4 || h != 3 {
+		t.Errorf("VGA aspect ratio %d:%d", w, h)
+	}
+}
+
+func TestLargestResolution(t *testing.T) {
+	tests := []struct {
+		w, h uint16
+		want Resolution
+	}{
+		{16, 9, HD()},
+		{4, 3, Resolution{Width: 1108, Height: 831}},
+		{1, 1, Resolution{Width: 960, Height: 960}},
+		{9, 16, Resolution{Width: 720, Height: 1280}},
+		{32, 18, HD()},
+	}
+	for _, tt := range tests {
+		got, err := LargestResolution(tt.w, tt.h)
+		if err != nil {
+			t.Errorf("%d:%d: %v", tt.w, tt.h, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%d:%d: got %v, want %v", tt.w, tt.h, got, tt.want)
+		}
+	}
+
+	if _, err := LargestResolution(0, 9); err == nil {
+		t.Error("expected error for 0:9")
+	}
+	if _, err := LargestResolution(1280, 1); err == nil {
+		t.Error("expected error for a ratio too wide to fit")
+	}
+}
+
+func TestResolutionEncoding(t *testing.T) {
+	b, err := json.Marshal(struct{ R Resolution }{PAL()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"R":"1024x576"}` {
+		t.Errorf("got %s", b)
+	}
+
+	var decoded struct{ R Resolution }
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded.R != PAL() {
+		t.Errorf("got %v, %v", decoded.R, err)
+	}
+	if err := json.Unmarshal([]byte(`{"R":"9x9"}`), &decoded); err == nil {
+		t.Error("expected error for an invalid resolution")
+	}
+
+	cif := CIF()
+	values, err := form.EncodeToValues(struct {
+		Resolution *Resolution `form:"Resolution,omitempty"`
+		Missing    *Resolution `form:"Missing,omitempty"`
+	}{Resolution: &cif})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values.Get("Resolution") != "320x240" || len(values) != 1 {
+		t.Errorf("got %v", values)
+	}
+}
@@ -0,0 +1,154 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// DefaultInterval keeps deletions well below Twilio's API rate limits.
+const DefaultInterval = 200 * time.Millisecond
+
+// Deleter deletes media, implemented by the Twilio client.
+type Deleter interface {
+	DeleteComposition(compositionSid string) error
+	DeleteRecording(recordingSid string) error
+}
+
+// ExecuteOptions of Execute.
+type ExecuteOptions struct {
+	// DryRun logs the deletions without making them.
+	DryRun bool
+
+	// Interval is the minimum time between two deletions, DefaultInterval when zero.
+	Interval time.Duration
+
+	// Audit receives an AuditEntry per media as a line of JSON, nothing is logged when nil.
+	Audit io.Writer
+}
+
+// Audit actions.
+const (
+	ActionDeleting = "deleting"
+	ActionDeleted  = "deleted"
+	ActionDryRun   = "dry-run"
+	ActionFailed   = "failed"
+	ActionHeld     = "held"
+)
+
+// AuditEntry records what happened to a media of the plan.
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Kind   Kind      `json:"kind"`
+	Sid    string    `json:"sid"`
+	Room   string    `json:"room_sid"`
+	Rule   string    `json:"rule,omitempty"`
+	Hold   string    `json:"hold,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Failure is media that could not be deleted.
+type Failure struct {
+	Item Item
+	Err  error
+}
+
+// Result is the outcome of executing a plan.
+type Result struct {
+	Deleted []Item
+	Failed  []Failure
+}
+
+// Execute deletes the media of the plan, one at a time and no faster than the interval.
+// Every deletion is logged before it is made and again with its outcome.
+// A failed deletion is logged and the next one is tried. Held media is only logged.
+// It stops early when the context is done or the audit log cannot be written,
+// since deleting without a trail is not allowed.
+func Execute(ctx context.Context, d Deleter, plan *Plan, opts ExecuteOptions) (*Result, error) {
+	if d == nil || plan == nil {
+		return nil, errors.New("Error, deleter and plan must not be nil.")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	var enc *json.Encoder
+	if opts.Audit != nil {
+		enc = json.NewEncoder(opts.Audit)
+	}
+	audit := func(it Item, action string, err error) error {
+		if enc == nil {
+			return nil
+		}
+		e := AuditEntry{
+			Time:   time.Now().UTC(),
+			Action: action,
+			Kind:   it.Kind,
+			Sid:    it.Sid,
+			Room:   it.RoomSid,
+			Rule:   it.Rule,
+			Hold:   it.Hold,
+		}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		return enc.Encode(e)
+	}
+
+	for _, it := range plan.Held {
+		if err := audit(it, ActionHeld, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &Result{}
+	var ticker *time.Ticker
+	for i, it := range plan.Delete {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		if opts.DryRun {
+			if err := audit(it, ActionDryRun, nil); err != nil {
+				return res, err
+			}
+			res.Deleted = append(res.Deleted, it)
+			continue
+		}
+
+		if i > 0 {
+			if ticker == nil {
+				ticker = time.NewTicker(opts.Interval)
+				defer ticker.Stop()
+			}
+			select {
+			case <-ctx.Done():
+				return res, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		if err := audit(it, ActionDeleting, nil); err != nil {
+			return res, err
+		}
+		err := deleteMedia(d, it.Media)
+		action := ActionDeleted
+		if err != nil {
+			action = ActionFailed
+			res.Failed = append(res.Failed, Failure{Item: it, Err: err})
+		} else {
+			res.Deleted = append(res.Deleted, it)
+		}
+		if err := audit(it, action, err); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func deleteMedia(d Deleter, m Media) error {
+	if m.Kind == KindComposition {
+		return d.DeleteComposition(m.Sid)
+	}
+	return d.DeleteRecording(m.Sid)
+}
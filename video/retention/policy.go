@@ -0,0 +1,343 @@
+// Package retention decides which compositions and recordings to delete from Twilio
+// and deletes them, once they are old enough and, if required, archived.
+//
+// A Policy is a list of rules and a legal hold list. Media is planned for deletion
+// when a rule matches it, in order, and nothing on the hold list covers it:
+// a hold always wins over the rules. Planning and executing are separate steps,
+// so a plan can be reviewed, or executed as a dry run, before anything is deleted.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+// Kind of media.
+type Kind string
+
+const (
+	KindComposition Kind = "composition"
+	KindRecording   Kind = "recording"
+)
+
+// Media is a composition or a recording, with the properties rules look at.
+type Media struct {
+	Kind        Kind      `json:"kind"`
+	Sid         string    `json:"sid"`
+	RoomSid     string    `json:"room_sid"`
+	Status      string    `json:"status"`
+	DateCreated time.Time `json:"date_created"`
+	Size        int64     `json:"size"`
+
+	composition *composition.Composition
+	recording   *recording.RecordingInstance
+}
+
+// CompositionMedia returns the media of a composition.
+func CompositionMedia(c *composition.Composition) Media {
+	return Media{
+		Kind:        KindComposition,
+		Sid:         c.Sid,
+		RoomSid:     c.RoomSid,
+		Status:      string(c.Status),
+		DateCreated: c.DateCreated,
+		Size:        int64(c.Size),
+		composition: c,
+	}
+}
+
+// RecordingMedia returns the media of a recording.
+func RecordingMedia(rec *recording.RecordingInstance) Media {
+	return Media{
+		Kind:        KindRecording,
+		Sid:         rec.Sid,
+		RoomSid:     rec.GroupingSids.RoomSid,
+		Status:      string(rec.Status),
+		DateCreated: rec.DateCreated,
+		Size:        int64(rec.Size),
+		recording:   rec,
+	}
+}
+
+// deleted reports whether Twilio already deleted the media.
+func (m Media) deleted() bool {
+	return m.Status == string(composition.StatusDeleted) || m.Status == string(recording.StatusDeleted)
+}
+
+// Rule selects media to delete. Its zero fields match any media,
+// but a rule must set at least one of them.
+type Rule struct {
+	// Name identifies the rule in plans and audit logs.
+	Name string `json:"name"`
+
+	// Kind of the media, both kinds when empty.
+	Kind Kind `json:"kind,omitempty"`
+
+	// OlderThan is the minimum age of the media, from its creation.
+	OlderThan time.Duration `json:"older_than,omitempty"`
+
+	// Rooms are patterns matched against the SID and the unique name of the media's room,
+	// an asterisk matches any characters.
+	Rooms []string `json:"rooms,omitempty"`
+
+	// Statuses of the media, such as completed or failed.
+	Statuses []string `json:"statuses,omitempty"`
+
+	// Archived, when set, requires the media to be archived, or not to be.
+	Archived *bool `json:"archived,omitempty"`
+}
+
+// Policy is the ordered rules and the legal holds.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+
+	// Holds are patterns of room SIDs, room unique names or media SIDs that are never deleted.
+	Holds []string `json:"holds,omitempty"`
+}
+
+// Validate checks every rule and returns all violations at once as video.ValidationErrors of *video.RuleError.
+func (p *Policy) Validate() error {
+	var errs video.ValidationErrors
+	names := map[string]bool{}
+	for i, r := range p.Rules {
+		for _, err := range r.validate() {
+			errs = append(errs, &video.RuleError{Index: i, Err: err})
+		}
+		if r.Name != "" && names[r.Name] {
+			errs = append(errs, &video.RuleError{Index: i, Err: fmt.Errorf("name %q is used by another rule", r.Name)})
+		}
+		names[r.Name] = true
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (r Rule) validate() []error {
+	var errs []error
+	if r.Name == "" {
+		errs = append(errs, errors.New("name must not be empty"))
+	}
+	if r.Kind != "" && r.Kind != KindComposition && r.Kind != KindRecording {
+		errs = append(errs, fmt.Errorf("kind must be %q or %q, got %q", KindComposition, KindRecording, r.Kind))
+	}
+	if r.OlderThan < 0 {
+		errs = append(errs, errors.New("older_than must not be negative"))
+	}
+	if r.Kind == "" && r.OlderThan == 0 && len(r.Rooms) == 0 && len(r.Statuses) == 0 && r.Archived == nil {
+		errs = append(errs, errors.New("a rule without conditions would delete every media"))
+	}
+	return errs
+}
+
+// ArchiveChecker tells whether media is archived, implemented by archive.Archiver.
+type ArchiveChecker interface {
+	IsCompositionArchived(ctx context.Context, c *composition.Composition) (bool, error)
+	IsRecordingArchived(ctx context.Context, rec *recording.RecordingInstance) (bool, error)
+}
+
+// PlannerOptions are the dependencies of a Planner.
+type PlannerOptions struct {
+	// Now is the time ages are computed at, time.Now when zero.
+	Now time.Time
+
+	// Archive is required when a rule sets Archived.
+	Archive ArchiveChecker
+
+	// RoomName returns the unique name of a room, so rules and holds can name rooms.
+	// Without it, rooms are only matched by SID.
+	RoomName func(roomSid string) (string, error)
+}
+
+// Planner evaluates the policy over media added page by page, and builds the deletion plan.
+type Planner struct {
+	policy *Policy
+	opts   PlannerOptions
+	names  map[string]string
+	plan   *Plan
+}
+
+// NewPlanner returns a planner of the policy, which must be valid.
+func NewPlanner(policy *Policy, opts PlannerOptions) (*Planner, error) {
+	if policy == nil {
+		return nil, errors.New("Error, policy must not be nil.")
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Archive == nil {
+		for _, r := range policy.Rules {
+			if r.Archived != nil {
+				return nil, fmt.Errorf("Error, rule %q needs an archive checker.", r.Name)
+			}
+		}
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	return &Planner{
+		policy: policy,
+		opts:   opts,
+		names:  map[string]string{},
+		plan:   &Plan{CreatedAt: opts.Now},
+	}, nil
+}
+
+// Item is media of a plan, with the rule deleting it or the hold keeping it.
+type Item struct {
+	Media
+	Rule string `json:"rule,omitempty"`
+	Hold string `json:"hold,omitempty"`
+}
+
+// Plan lists the media to delete and the media a hold keeps from being deleted.
+type Plan struct {
+	CreatedAt time.Time `json:"created_at"`
+	Delete    []Item    `json:"delete"`
+	Held      []Item    `json:"held"`
+
+	// Retained counts the media no rule matched.
+	Retained int `json:"retained"`
+}
+
+// Size returns the number of bytes the plan frees.
+func (p *Plan) Size() int64 {
+	var n int64
+	for _, it := range p.Delete {
+		n += it.Size
+	}
+	return n
+}
+
+// AddCompositions evaluates a page of compositions.
+func (p *Planner) AddCompositions(ctx context.Context, comps []composition.Composition) error {
+	for i := range comps {
+		if err := p.add(ctx, CompositionMedia(&comps[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddRecordings evaluates a page of recordings.
+func (p *Planner) AddRecordings(ctx context.Context, recs []recording.RecordingInstance) error {
+	for i := range recs {
+		if err := p.add(ctx, RecordingMedia(&recs[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Plan returns the plan of the media added so far.
+func (p *Planner) Plan() *Plan {
+	return p.plan
+}
+
+func (p *Planner) add(ctx context.Context, m Media) error {
+	if m.deleted() {
+		return nil
+	}
+	room, err := p.roomName(m.RoomSid)
+	if err != nil {
+		return err
+	}
+	for _, r := range p.policy.Rules {
+		ok, err := p.match(ctx, r, m, room)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if hold, held := p.hold(m, room); held {
+			p.plan.Held = append(p.plan.Held, Item{Media: m, Rule: r.Name, Hold: hold})
+		} else {
+			p.plan.Delete = append(p.plan.Delete, Item{Media: m, Rule: r.Name})
+		}
+		return nil
+	}
+	p.plan.Retained++
+	return nil
+}
+
+// match checks the cheap conditions first, the archive is only asked when they all hold.
+func (p *Planner) match(ctx context.Context, r Rule, m Media, room string) (bool, error) {
+	if r.Kind != "" && r.Kind != m.Kind {
+		return false, nil
+	}
+	if p.opts.Now.Sub(m.DateCreated) < r.OlderThan {
+		return false, nil
+	}
+	if len(r.Statuses) > 0 && !contains(r.Statuses, m.Status) {
+		return false, nil
+	}
+	if len(r.Rooms) > 0 {
+		if _, ok := matchAny(r.Rooms, m.RoomSid, room); !ok {
+			return false, nil
+		}
+	}
+	if r.Archived == nil {
+		return true, nil
+	}
+	archived, err := p.archived(ctx, m)
+	if err != nil {
+		return false, err
+	}
+	return archived == *r.Archived, nil
+}
+
+func (p *Planner) hold(m Media, room string) (string, bool) {
+	return matchAny(p.policy.Holds, m.Sid, m.RoomSid, room)
+}
+
+func (p *Planner) archived(ctx context.Context, m Media) (bool, error) {
+	if m.composition != nil {
+		return p.opts.Archive.IsCompositionArchived(ctx, m.composition)
+	}
+	return p.opts.Archive.IsRecordingArchived(ctx, m.recording)
+}
+
+// roomName returns the room's unique name, looked up once per room.
+func (p *Planner) roomName(roomSid string) (string, error) {
+	if p.opts.RoomName == nil || roomSid == "" {
+		return "", nil
+	}
+	if name, ok := p.names[roomSid]; ok {
+		return name, nil
+	}
+	name, err := p.opts.RoomName(roomSid)
+	if err != nil {
+		return "", err
+	}
+	p.names[roomSid] = name
+	return name, nil
+}
+
+// matchAny returns the first pattern matching one of the non-empty names.
+func matchAny(patterns []string, names ...string) (string, bool) {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if name != "" && video.MatchSource(pattern, name) {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
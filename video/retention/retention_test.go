@@ -0,0 +1,245 @@
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+	"github.com/matthxwpavin/twilio-compositions/video/composition"
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+var now = time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+
+func daysAgo(n int) time.Time {
+	return now.Add(-time.Duration(n) * 24 * time.Hour)
+}
+
+type fakeArchive map[string]bool
+
+func (a fakeArchive) IsCompositionArchived(ctx context.Context, c *composition.Composition) (bool, error) {
+	return a[c.Sid], nil
+}
+
+func (a fakeArchive) IsRecordingArchived(ctx context.Context, rec *recording.RecordingInstance) (bool, error) {
+	return a[rec.Sid], nil
+}
+
+func recordingOf(sid, roomSid string, status recording.Status, created time.Time) recording.RecordingInstance {
+	rec := recording.RecordingInstance{Sid: sid, Status: status, DateCreated: created, Size: 10}
+	rec.GroupingSids.RoomSid = roomSid
+	return rec
+}
+
+func TestPlan(t *testing.T) {
+	archived := true
+	policy := &Policy{
+		Rules: []Rule{
+			{Name: "failed", Statuses: []string{"failed"}},
+			{Name: "archived-30d", OlderThan: 30 * 24 * time.Hour, Archived: &archived},
+			{Name: "demo-rooms", Kind: KindRecording, Rooms: []string{"demo-*"}, OlderThan: 24 * time.Hour},
+		},
+		Holds: []string{"RM-legal", "CJ-keep"},
+	}
+	p, err := NewPlanner(policy, PlannerOptions{
+		Now:     now,
+		Archive: fakeArchive{"CJ-old": true, "CJ-keep": true, "RT-held": true},
+		RoomName: func(roomSid string) (string, error) {
+			return map[string]string{"RM-demo": "demo-42"}[roomSid], nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	err = p.AddCompositions(ctx, []composition.Composition{
+		{Sid: "CJ-old", RoomSid: "RM1", Status: composition.StatusCompleted, DateCreated: daysAgo(40), Size: 100},
+		{Sid: "CJ-new", RoomSid: "RM1", Status: composition.StatusCompleted, DateCreated: daysAgo(3)},
+		{Sid: "CJ-unarchived", RoomSid: "RM1", Status: composition.StatusCompleted, DateCreated: daysAgo(40)},
+		{Sid: "CJ-keep", RoomSid: "RM1", Status: composition.StatusCompleted, DateCreated: daysAgo(40)},
+		{Sid: "CJ-failed", RoomSid: "RM1", Status: composition.StatusFailed, DateCreated: daysAgo(1)},
+		{Sid: "CJ-gone", RoomSid: "RM1", Status: composition.StatusDeleted, DateCreated: daysAgo(90)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.AddRecordings(ctx, []recording.RecordingInstance{
+		recordingOf("RT-held", "RM-legal", recording.StatusCompleted, daysAgo(60)),
+		recordingOf("RT-demo", "RM-demo", recording.StatusCompleted, daysAgo(2)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := p.Plan()
+	want := map[string]string{"CJ-failed": "failed", "CJ-old": "archived-30d", "RT-demo": "demo-rooms"}
+	if len(plan.Delete) != len(want) {
+		t.Fatalf("got %+v", plan.Delete)
+	}
+	for _, it := range plan.Delete {
+		if want[it.Sid] != it.Rule {
+			t.Errorf("%s deleted by %q, want %q", it.Sid, it.Rule, want[it.Sid])
+		}
+	}
+	if len(plan.Held) != 2 || plan.Held[0].Hold != "CJ-keep" || plan.Held[1].Hold != "RM-legal" {
+		t.Errorf("held %+v", plan.Held)
+	}
+	if plan.Retained != 2 {
+		t.Errorf("retained %d", plan.Retained)
+	}
+	if plan.Size() != 110 {
+		t.Errorf("size %d", plan.Size())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Name: "all"},
+		{Name: "x", Kind: "video", OlderThan: -1},
+		{Name: "x", Statuses: []string{"failed"}},
+	}}
+	err := policy.Validate()
+	errs, ok := err.(video.ValidationErrors)
+	if !ok || len(errs) != 4 {
+		t.Fatalf("got %v", err)
+	}
+	var re *video.RuleError
+	if !errors.As(errs[3], &re) || re.Index != 2 {
+		t.Errorf("got %v", errs[3])
+	}
+
+	archived := false
+	if _, err := NewPlanner(&Policy{Rules: []Rule{{Name: "a", Archived: &archived}}}, PlannerOptions{}); err == nil {
+		t.Error("expected an error without archive checker")
+	}
+}
+
+type fakeDeleter struct {
+	deleted []string
+	times   []time.Time
+}
+
+func (d *fakeDeleter) DeleteComposition(sid string) error {
+	return d.delete(sid)
+}
+
+func (d *fakeDeleter) DeleteRecording(sid string) error {
+	return d.delete(sid)
+}
+
+func (d *fakeDeleter) delete(sid string) error {
+	d.times = append(d.times, time.Now())
+	if sid == "RT-broken" {
+		return errors.New("boom")
+	}
+	d.deleted = append(d.deleted, sid)
+	return nil
+}
+
+func testPlan() *Plan {
+	return &Plan{
+		Delete: []Item{
+			{Media: Media{Kind: KindComposition, Sid: "CJ1"}, Rule: "r"},
+			{Media: Media{Kind: KindRecording, Sid: "RT-broken"}, Rule: "r"},
+			{Media: Media{Kind: KindRecording, Sid: "RT2"}, Rule: "r"},
+		},
+		Held: []Item{{Media: Media{Kind: KindRecording, Sid: "RT3"}, Rule: "r", Hold: "RM-legal"}},
+	}
+}
+
+func auditActions(t *testing.T, log *bytes.Buffer) []string {
+	var actions []string
+	sc := bufio.NewScanner(log)
+	for sc.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		actions = append(actions, e.Sid+":"+e.Action)
+	}
+	return actions
+}
+
+func TestExecute(t *testing.T) {
+	d := &fakeDeleter{}
+	log := &bytes.Buffer{}
+	interval := 20 * time.Millisecond
+	res, err := Execute(context.Background(), d, testPlan(), ExecuteOptions{Interval: interval, Audit: log})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Deleted) != 2 || len(res.Failed) != 1 || res.Failed[0].Item.Sid != "RT-broken" {
+		t.Fatalf("got %+v", res)
+	}
+	for i := 1; i < len(d.times); i++ {
+		if gap := d.times[i].Sub(d.times[i-1]); gap < interval/2 {
+			t.Errorf("deletions %d and %d are %v apart", i-1, i, gap)
+		}
+	}
+	want := []string{
+		"RT3:held",
+		"CJ1:deleting", "CJ1:deleted",
+		"RT-broken:deleting", "RT-broken:failed",
+		"RT2:deleting", "RT2:deleted",
+	}
+	got := auditActions(t, log)
+	if len(got) != len(want) {
+		t.Fatalf("audit %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("audit %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExecuteDryRun(t *testing.T) {
+	d := &fakeDeleter{}
+	log := &bytes.Buffer{}
+	res, err := Execute(context.Background(), d, testPlan(), ExecuteOptions{DryRun: true, Audit: log})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.times) != 0 || len(res.Deleted) != 3 {
+		t.Fatalf("got %+v, %d deletions", res, len(d.times))
+	}
+	if got := auditActions(t, log); len(got) != 4 || got[1] != "CJ1:dry-run" {
+		t.Errorf("audit %v", got)
+	}
+}
+
+type failingWriter struct {
+	left int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.left == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.left--
+	return len(p), nil
+}
+
+func TestExecuteStopsWithoutTrail(t *testing.T) {
+	d := &fakeDeleter{}
+	// The held entry and both of the first deletion's are written, the second deletion's is not.
+	res, err := Execute(context.Background(), d, testPlan(), ExecuteOptions{
+		Interval: time.Millisecond,
+		Audit:    &failingWriter{left: 3},
+	})
+	if err == nil {
+		t.Fatal("expected the audit error")
+	}
+	if len(d.times) != 1 || len(d.deleted) != 1 || d.deleted[0] != "CJ1" {
+		t.Errorf("deleted %v in %d calls", d.deleted, len(d.times))
+	}
+	if res == nil || len(res.Deleted) != 1 || len(res.Failed) != 0 {
+		t.Errorf("got %+v", res)
+	}
+}
@@ -0,0 +1,53 @@
+package rooms
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// More info https://www.twilio.com/docs/video/api/recording-rules
+
+type RecordingRuleType = RuleType
+
+const (
+	RecordingRuleInclude = RuleTypeInclude
+	RecordingRuleExclude = RuleTypeExclude
+)
+
+// RecordingRule includes or excludes the tracks matching all of its filters from recording.
+type RecordingRule = TrackRule
+
+// RecordingRuleSet is the RecordingRules parameter of a new room,
+// sent as a JSON object holding the rules.
+type RecordingRuleSet struct {
+	Rules []RecordingRule `json:"rules"`
+}
+
+func (s RecordingRuleSet) MarshalText() ([]byte, error) {
+	// The alias drops MarshalText, which json would otherwise call back.
+	type fields RecordingRuleSet
+	if s.Rules == nil {
+		s.Rules = []RecordingRule{}
+	}
+	return json.Marshal(fields(s))
+}
+
+// RoomRecordingRules are the recording rules of a room.
+type RoomRecordingRules struct {
+	RoomSid     string          `json:"room_sid"`
+	Rules       []RecordingRule `json:"rules"`
+	DateCreated *time.Time      `json:"date_created"`
+	DateUpdated *time.Time      `json:"date_updated"`
+}
+
+// ValidateRecordingRules checks every rule and returns all violations at once
+// as video.ValidationErrors of *video.RuleError.
+func ValidateRecordingRules(rules []RecordingRule) error {
+	return ValidateRules(rules)
+}
+
+// IsRecorded predicts whether the rules record the track. The rules apply in order,
+// so a later matching rule overrides an earlier one, and a track no rule matches is not recorded.
+func IsRecorded(rules []RecordingRule, track RuleTrack) bool {
+	return Included(rules, track)
+}
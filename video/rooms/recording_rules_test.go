@@ -0,0 +1,63 @@
+package rooms
+
+import (
+	"testing"
+
+	"github.com/ajg/form"
+)
+
+func TestRecordingRulesFormEncoding(t *testing.T) {
+	values, err := form.EncodeToValues(&RoomPostParams{
+		RecordingRules: &RecordingRuleSet{Rules: []RecordingRule{
+			{Type: RecordingRuleInclude, All: true},
+			{Type: RecordingRuleExclude, Kind: TrackKindVideo, Publisher: "alice"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"rules":[{"type":"include","all":true},{"type":"exclude","kind":"video","publisher":"alice"}]}`
+	if got := values.Get("RecordingRules"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	values, err = form.EncodeToValues(&RoomPostParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := values["RecordingRules"]; ok {
+		t.Errorf("got RecordingRules %q without rules", values.Get("RecordingRules"))
+	}
+}
+
+func TestIsRecorded(t *testing.T) {
+	tracks := []RuleTrack{
+		{Sid: "MTteachercamera", Name: "camera", Kind: TrackKindVideo, PublisherSid: "PA1", PublisherIdentity: "teacher"},
+		{Sid: "MTteachermic", Name: "mic", Kind: TrackKindAudio, PublisherSid: "PA1", PublisherIdentity: "teacher"},
+		{Sid: "MTstudentcamera", Name: "camera", Kind: TrackKindVideo, PublisherSid: "PA2", PublisherIdentity: "student1"},
+		{Sid: "MTstudentmic", Name: "mic", Kind: TrackKindAudio, PublisherSid: "PA2", PublisherIdentity: "student1"},
+	}
+	rules := []RecordingRule{
+		{Type: RecordingRuleInclude, Kind: TrackKindAudio},
+		{Type: RecordingRuleInclude, Publisher: "teacher"},
+		{Type: RecordingRuleExclude, Track: "MTteachermic"},
+	}
+	if err := ValidateRecordingRules(rules); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, track := range tracks {
+		if IsRecorded(rules, track) {
+			got = append(got, track.PublisherIdentity+"/"+track.Name)
+		}
+	}
+	want := []string{"teacher/camera", "student1/mic"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if err := ValidateRecordingRules([]RecordingRule{{Type: RecordingRuleInclude}}); err == nil {
+		t.Error("rule without filters passed validation")
+	}
+}
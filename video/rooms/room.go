@@ -52,6 +52,19 @@ const (
 	TypeP2P        RoomType = "peer-to-peer" // P2P rooms
 )
 
+type RoomStatus string
+
+const (
+	RoomStatusInProgress RoomStatus = "in-progress"
+	RoomStatusCompleted  RoomStatus = "completed"
+	RoomStatusFailed     RoomStatus = "failed"
+)
+
+// Ended reports whether the room will not change anymore.
+func (s RoomStatus) Ended() bool {
+	return s == RoomStatusCompleted || s == RoomStatusFailed
+}
+
 type RoomInstanceList struct {
 	Rooms []RoomInstance `json:"rooms"`
 	Meta  struct {
@@ -76,7 +89,7 @@ type RoomInstance struct {
 	DateUpdated time.Time `json:"date_updated"`
 
 	// The status of the rooms. Can be: in-progress, failed, or completed.
-	Status string `json:"status"`
+	Status RoomStatus `json:"status"`
 
 	// The type of rooms. Can be: go, peer-to-peer, group-small, or group. The default value is group.
 	Type string `json:"type"`
@@ -188,13 +201,35 @@ type RoomPostParams struct {
 	MediaRegions *string `form:"MediaRegions,omitempty"`
 
 	// A collection of Recording Rules that describe how to include or exclude matching tracks for recording
-	RecordingRules interface{} `form:"RecordingRules,omitempty"`
+	RecordingRules *RecordingRuleSet `form:"RecordingRules,omitempty"`
 
 	// When set to true, indicates that the participants in the room will only publish audio.
 	// No video tracks will be allowed. Group rooms only.
 	AudioOny *bool `form:"AudioOnly,omitempty"`
 }
 
+type RoomGetParams struct {
+	// Read only the rooms with this status. Can be: in-progress (default) or completed.
+	Status *RoomStatus `form:"Status,omitempty"`
+
+	// Read only rooms with the this unique_name.
+	UniqueName *string `form:"UniqueName,omitempty"`
+
+	// Read only rooms that started on or after this date, given as YYYY-MM-DD.
+	DateCreatedAfter *string `form:"DateCreatedAfter,omitempty"`
+
+	// Read only rooms that started before this date, given as YYYY-MM-DD.
+	DateCreatedBefore *string `form:"DateCreatedBefore,omitempty"`
+
+	// How many resources to return in each list page. The default is 50, and the maximum is 1000.
+	PageSize *uint `form:"PageSize,omitempty"`
+}
+
+type RoomUpdateParams struct {
+	// The new status of the resource. Set to completed to end the room.
+	Status RoomStatus `form:"Status"`
+}
+
 const (
 	TrackKindAudio = "audio"
 	TrackKindVideo = "video"
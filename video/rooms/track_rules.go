@@ -0,0 +1,104 @@
+package rooms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/matthxwpavin/twilio-compositions/video"
+)
+
+// RuleType tells whether a rule includes or excludes the tracks it matches.
+type RuleType string
+
+const (
+	RuleTypeInclude RuleType = "include"
+	RuleTypeExclude RuleType = "exclude"
+)
+
+// TrackRule includes or excludes the tracks matching all of its filters,
+// the way both recording rules and subscribe rules select tracks.
+// All matches every track and cannot be combined with the other filters.
+type TrackRule struct {
+	Type RuleType `json:"type"`
+	All  bool     `json:"all,omitempty"`
+
+	// One of TrackKindAudio, TrackKindVideo or TrackKindData.
+	Kind string `json:"kind,omitempty"`
+
+	// The identity or SID of the participant publishing the track.
+	Publisher string `json:"publisher,omitempty"`
+
+	// The name or SID of the track.
+	Track string `json:"track,omitempty"`
+}
+
+// RuleTrack is a track published in the room, with what rules match it on.
+type RuleTrack struct {
+	Sid               string
+	Name              string
+	Kind              string
+	PublisherSid      string
+	PublisherIdentity string
+}
+
+// ValidateRules checks every rule and returns all violations at once
+// as video.ValidationErrors of *video.RuleError.
+func ValidateRules(rules []TrackRule) error {
+	var errs video.ValidationErrors
+	for i, r := range rules {
+		for _, err := range r.validate() {
+			errs = append(errs, &video.RuleError{Index: i, Err: err})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (r TrackRule) validate() []error {
+	var errs []error
+	if r.Type != RuleTypeInclude && r.Type != RuleTypeExclude {
+		errs = append(errs, fmt.Errorf("type must be %q or %q, got %q", RuleTypeInclude, RuleTypeExclude, r.Type))
+	}
+	hasFilter := r.Kind != "" || r.Publisher != "" || r.Track != ""
+	switch {
+	case r.All && hasFilter:
+		errs = append(errs, errors.New("all cannot be combined with kind, publisher or track"))
+	case !r.All && !hasFilter:
+		errs = append(errs, errors.New("rule must have all or at least one of kind, publisher or track"))
+	}
+	if r.Kind != "" && r.Kind != TrackKindAudio && r.Kind != TrackKindVideo && r.Kind != TrackKindData {
+		errs = append(errs, fmt.Errorf("kind must be audio, video or data, got %q", r.Kind))
+	}
+	return errs
+}
+
+// Matches reports whether the track passes all of the rule's filters.
+func (r TrackRule) Matches(track RuleTrack) bool {
+	if r.All {
+		return true
+	}
+	if r.Kind != "" && r.Kind != track.Kind {
+		return false
+	}
+	if r.Publisher != "" && r.Publisher != track.PublisherSid && r.Publisher != track.PublisherIdentity {
+		return false
+	}
+	if r.Track != "" && r.Track != track.Sid && r.Track != track.Name {
+		return false
+	}
+	return true
+}
+
+// Included tells whether the rules include the track. The rules apply in order,
+// so a later matching rule overrides an earlier one, and a track no rule matches is not included.
+func Included(rules []TrackRule, track RuleTrack) bool {
+	included := false
+	for _, r := range rules {
+		if r.Matches(track) {
+			included = r.Type == RuleTypeInclude
+		}
+	}
+	return included
+}
@@ -0,0 +1,37 @@
+package rooms
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WaitEnded calls get every interval until the room it returns is completed or failed,
+// or until ctx is done. The interval must be positive.
+func WaitEnded(
+	ctx context.Context,
+	get func() (*RoomInstance, error),
+	interval time.Duration,
+) (*RoomInstance, error) {
+	if interval <= 0 {
+		return nil, errors.New("Interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		room, err := get()
+		if err != nil {
+			return nil, err
+		}
+		if room.Status.Ended() {
+			return room, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package rooms
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaitEnded(t *testing.T) {
+	statuses := []RoomStatus{RoomStatusInProgress, RoomStatusInProgress, RoomStatusCompleted}
+	calls := 0
+	room, err := WaitEnded(context.Background(), func() (*RoomInstance, error) {
+		r := &RoomInstance{Status: statuses[calls]}
+		calls++
+		return r, nil
+	}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if room.Status != RoomStatusCompleted || calls != 3 {
+		t.Errorf("got status %s after %d calls", room.Status, calls)
+	}
+}
+
+func TestWaitEndedRejectsInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := WaitEnded(context.Background(), func() (*RoomInstance, error) {
+			t.Fatal("polled with a non-positive interval")
+			return nil, nil
+		}, interval)
+		if err == nil {
+			t.Errorf("%v: expected an error", interval)
+		}
+	}
+}
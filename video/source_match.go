@@ -0,0 +1,31 @@
+package video
+
+import "strings"
+
+// MatchSource reports whether a source entry of a layout or of audio_sources matches a track name.
+// Like Twilio, the asterisk matches zero or more characters, so "student*" matches
+// both "student" and "studentTeam". Other characters match literally.
+func MatchSource(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+
+	// The name must start with the part before the first asterisk
+	// and end with the part after the last one.
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := parts[len(parts)-1]
+
+	// Parts in between are matched leftmost, which is enough since asterisks can stretch.
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, last)
+}
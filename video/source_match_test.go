@@ -0,0 +1,30 @@
+package video
+
+import "testing"
+
+func TestMatchSource(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*", "anything", true},
+		{"*", "", true},
+		{"student", "student", true},
+		{"student", "studentTeam", false},
+		{"student*", "student", true},
+		{"student*", "studentTeam", true},
+		{"student*", "teacher", false},
+		{"*Team", "studentTeam", true},
+		{"*Team", "TeamLead", false},
+		{"s*t*m", "studentTeam", true},
+		{"s*t*m", "studentTeams", false},
+		{"a*a", "a", false},
+		{"a*a", "aa", true},
+		{"*cam*", "teacher-camera", true},
+	}
+	for _, tt := range tests {
+		if got := MatchSource(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchSource(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package timeline aligns the recordings of a room on a common clock,
+// so offline mixers can lay the tracks out without probing every file.
+//
+// Times are relative to the start of the room. Every recording has its own offset,
+// the millisecond its media starts on Twilio's video clock, but Twilio does not give the room's.
+// The recording with the smallest offset is placed at its creation time, and the others
+// at their offset's distance from it, so they keep the clock's precision.
+// Recordings without an offset fall back to their creation time, which is only precise to the second.
+package timeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+// Track is a recording placed on the room's timeline, in milliseconds from the start of the room.
+type Track struct {
+	RecordingSid string              `json:"recording_sid"`
+	SourceSid    string              `json:"source_sid"`
+	TrackName    string              `json:"track_name"`
+	Type         recording.Type      `json:"type"`
+	Codec        recording.Codec     `json:"codec"`
+	Container    recording.Container `json:"container"`
+	StartMs      int64               `json:"start_ms"`
+	EndMs        int64               `json:"end_ms"`
+}
+
+// Start returns the time the track starts after the room started.
+func (t Track) Start() time.Duration {
+	return time.Duration(t.StartMs) * time.Millisecond
+}
+
+// End returns the time the track ends after the room started.
+func (t Track) End() time.Duration {
+	return time.Duration(t.EndMs) * time.Millisecond
+}
+
+// Pair is an audio track and the video track published alongside it.
+// Either can be nil when the participant only published one kind at the time.
+type Pair struct {
+	Audio *Track `json:"audio"`
+	Video *Track `json:"video"`
+}
+
+// Gap is an interval without any media, in milliseconds from the start of the room.
+type Gap struct {
+	StartMs int64 `json:"start_ms"`
+	EndMs   int64 `json:"end_ms"`
+}
+
+// Participant lists the tracks of one participant.
+// Its gaps are the intervals between its first and last media when none of its tracks was recorded,
+// such as a reconnection.
+type Participant struct {
+	ParticipantSid string `json:"participant_sid"`
+	Pairs          []Pair `json:"pairs"`
+	Gaps           []Gap  `json:"gaps"`
+}
+
+// Alignment is the timeline of a room's recordings, encoded as JSON for mixers.
+// Its gaps are the intervals after the room started and before its last media when nothing was recorded.
+type Alignment struct {
+	RoomSid   string    `json:"room_sid"`
+	RoomStart time.Time `json:"room_start"`
+
+	// The start of the room on Twilio's video clock, in milliseconds, as estimated from
+	// the recording with the smallest offset. Zero when no recording has an offset.
+	EpochOffsetMs int64 `json:"epoch_offset_ms"`
+
+	DurationMs   int64         `json:"duration_ms"`
+	Participants []Participant `json:"participants"`
+	Gaps         []Gap         `json:"gaps"`
+}
+
+// Align places the room's recordings on its timeline. Recordings of other rooms,
+// and the ones without media (failed, deleted or absent) are skipped.
+// A zero roomStart uses the creation of the first recording instead.
+// Participants are sorted by their first media, and their pairs by start.
+func Align(roomSid string, roomStart time.Time, recs []recording.RecordingInstance) *Alignment {
+	var kept []recording.RecordingInstance
+	for _, rec := range recs {
+		if roomSid != "" && rec.GroupingSids.RoomSid != "" && rec.GroupingSids.RoomSid != roomSid {
+			continue
+		}
+		switch rec.Status {
+		case recording.StatusFailed, recording.StatusDeleted, recording.StatusAbsent:
+			continue
+		}
+		kept = append(kept, rec)
+	}
+
+	if roomStart.IsZero() {
+		for _, rec := range kept {
+			if roomStart.IsZero() || rec.DateCreated.Before(roomStart) {
+				roomStart = rec.DateCreated
+			}
+		}
+	}
+
+	a := &Alignment{
+		RoomSid:      roomSid,
+		RoomStart:    roomStart,
+		Participants: []Participant{},
+		Gaps:         []Gap{},
+	}
+	var first *recording.RecordingInstance
+	for i, rec := range kept {
+		if rec.Offset != 0 && (first == nil || rec.Offset < first.Offset) {
+			first = &kept[i]
+		}
+	}
+	if first != nil {
+		created := first.DateCreated.Sub(roomStart)
+		if created < 0 {
+			created = 0
+		}
+		a.EpochOffsetMs = int64(first.Offset) - created.Milliseconds()
+	}
+	byParticipant := map[string][]Track{}
+	var order []string
+	var all []Track
+	for _, rec := range kept {
+		start := time.Duration(int64(rec.Offset)-a.EpochOffsetMs) * time.Millisecond
+		if rec.Offset == 0 {
+			start = rec.DateCreated.Sub(roomStart)
+		}
+		if start < 0 {
+			start = 0
+		}
+		track := Track{
+			RecordingSid: rec.Sid,
+			SourceSid:    rec.SourceSid,
+			TrackName:    rec.TrackName,
+			Type:         rec.Type,
+			Codec:        rec.Codec,
+			Container:    rec.ContainerFormat,
+			StartMs:      start.Milliseconds(),
+			EndMs:        (start + rec.GetDuration()).Milliseconds(),
+		}
+		sid := rec.GroupingSids.ParticipantSid
+		if _, ok := byParticipant[sid]; !ok {
+			order = append(order, sid)
+		}
+		byParticipant[sid] = append(byParticipant[sid], track)
+		all = append(all, track)
+		if track.EndMs > a.DurationMs {
+			a.DurationMs = track.EndMs
+		}
+	}
+
+	for _, sid := range order {
+		tracks := byParticipant[sid]
+		a.Participants = append(a.Participants, Participant{
+			ParticipantSid: sid,
+			Pairs:          pairTracks(tracks),
+			Gaps:           gaps(tracks, firstStart(tracks)),
+		})
+	}
+	sort.SliceStable(a.Participants, func(i, j int) bool {
+		return firstStart(byParticipant[a.Participants[i].ParticipantSid]) <
+			firstStart(byParticipant[a.Participants[j].ParticipantSid])
+	})
+	a.Gaps = gaps(all, 0)
+	return a
+}
+
+func firstStart(tracks []Track) int64 {
+	first := tracks[0].StartMs
+	for _, t := range tracks[1:] {
+		if t.StartMs < first {
+			first = t.StartMs
+		}
+	}
+	return first
+}
+
+// pairTracks pairs every audio track with the unpaired video track overlapping it the most.
+func pairTracks(tracks []Track) []Pair {
+	var audios, videos []Track
+	for _, t := range tracks {
+		switch t.Type {
+		case recording.TypeAudio:
+			audios = append(audios, t)
+		case recording.TypeVideo:
+			videos = append(videos, t)
+		}
+	}
+	byStart := func(ts []Track) {
+		sort.SliceStable(ts, func(i, j int) bool { return ts[i].StartMs < ts[j].StartMs })
+	}
+	byStart(audios)
+	byStart(videos)
+
+	paired := make([]bool, len(videos))
+	pairs := []Pair{}
+	for i := range audios {
+		best, bestOverlap := -1, int64(0)
+		for j := range videos {
+			if paired[j] {
+				continue
+			}
+			if o := overlap(audios[i], videos[j]); o > bestOverlap {
+				best, bestOverlap = j, o
+			}
+		}
+		p := Pair{Audio: &audios[i]}
+		if best >= 0 {
+			paired[best] = true
+			p.Video = &videos[best]
+		}
+		pairs = append(pairs, p)
+	}
+	for j := range videos {
+		if !paired[j] {
+			pairs = append(pairs, Pair{Video: &videos[j]})
+		}
+	}
+
+	start := func(p Pair) int64 {
+		if p.Audio != nil && (p.Video == nil || p.Audio.StartMs <= p.Video.StartMs) {
+			return p.Audio.StartMs
+		}
+		return p.Video.StartMs
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return start(pairs[i]) < start(pairs[j]) })
+	return pairs
+}
+
+func overlap(a, b Track) int64 {
+	start, end := a.StartMs, a.EndMs
+	if b.StartMs > start {
+		start = b.StartMs
+	}
+	if b.EndMs < end {
+		end = b.EndMs
+	}
+	if end < start {
+		return 0
+	}
+	return end - start
+}
+
+// gaps returns the intervals from `from` to the end of the last track that no track covers.
+func gaps(tracks []Track, from int64) []Gap {
+	sorted := append([]Track{}, tracks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartMs < sorted[j].StartMs })
+
+	ret := []Gap{}
+	covered := from
+	for _, t := range sorted {
+		if t.StartMs > covered {
+			ret = append(ret, Gap{StartMs: covered, EndMs: t.StartMs})
+		}
+		if t.EndMs > covered {
+			covered = t.EndMs
+		}
+	}
+	return ret
+}
@@ -0,0 +1,174 @@
+package timeline
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matthxwpavin/twilio-compositions/video/recording"
+)
+
+var roomStart = time.Date(2021, 5, 18, 10, 0, 0, 0, time.UTC)
+
+func rec(sid, participant string, typ recording.Type, startSec, durationSec int) recording.RecordingInstance {
+	r := recording.RecordingInstance{
+		Sid:         sid,
+		Status:      recording.StatusCompleted,
+		DateCreated: roomStart.Add(time.Duration(startSec) * time.Second),
+		SourceSid:   "MT" + sid,
+		TrackName:   participant + "-" + string(typ),
+		Type:        typ,
+		Duration:    durationSec,
+		Offset:      1000000 + startSec*1000,
+	}
+	r.GroupingSids.RoomSid = "RM1"
+	r.GroupingSids.ParticipantSid = participant
+	return r
+}
+
+func TestAlign(t *testing.T) {
+	recs := []recording.RecordingInstance{
+		// Bob joins later than alice.
+		rec("RT1", "PA_bob", recording.TypeAudio, 30, 60),
+		rec("RT2", "PA_bob", recording.TypeVideo, 31, 59),
+		// Alice reconnects after 20 seconds away, publishing audio and video again.
+		rec("RT3", "PA_alice", recording.TypeVideo, 5, 20),
+		rec("RT4", "PA_alice", recording.TypeAudio, 5, 20),
+		rec("RT5", "PA_alice", recording.TypeAudio, 45, 10),
+		rec("RT6", "PA_alice", recording.TypeVideo, 46, 9),
+		// Screen share without audio.
+		rec("RT7", "PA_alice", recording.TypeVideo, 47, 5),
+	}
+	other := rec("RT8", "PA_carol", recording.TypeAudio, 0, 10)
+	other.GroupingSids.RoomSid = "RM2"
+	failed := rec("RT9", "PA_bob", recording.TypeAudio, 0, 10)
+	failed.Status = recording.StatusFailed
+	recs = append(recs, other, failed)
+
+	a := Align("RM1", roomStart, recs)
+
+	if a.EpochOffsetMs != 1000000 || a.DurationMs != 90000 {
+		t.Errorf("got offset %d and duration %d", a.EpochOffsetMs, a.DurationMs)
+	}
+	if want := []Gap{{0, 5000}, {25000, 30000}}; !reflect.DeepEqual(a.Gaps, want) {
+		t.Errorf("got room gaps %v, want %v", a.Gaps, want)
+	}
+	if len(a.Participants) != 2 {
+		t.Fatalf("got %d participants, want 2", len(a.Participants))
+	}
+
+	alice, bob := a.Participants[0], a.Participants[1]
+	if alice.ParticipantSid != "PA_alice" || bob.ParticipantSid != "PA_bob" {
+		t.Fatalf("got participants %s, %s", alice.ParticipantSid, bob.ParticipantSid)
+	}
+	if want := []Gap{{25000, 45000}}; !reflect.DeepEqual(alice.Gaps, want) {
+		t.Errorf("got alice gaps %v, want %v", alice.Gaps, want)
+	}
+	if len(bob.Gaps) != 0 {
+		t.Errorf("got bob gaps %v", bob.Gaps)
+	}
+
+	wantPairs := [][2]string{{"RT4", "RT3"}, {"RT5", "RT6"}, {"", "RT7"}}
+	if len(alice.Pairs) != len(wantPairs) {
+		t.Fatalf("got %d pairs for alice, want %d", len(alice.Pairs), len(wantPairs))
+	}
+	for i, p := range alice.Pairs {
+		var got [2]string
+		if p.Audio != nil {
+			got[0] = p.Audio.RecordingSid
+		}
+		if p.Video != nil {
+			got[1] = p.Video.RecordingSid
+		}
+		if got != wantPairs[i] {
+			t.Errorf("pair %d: got %v, want %v", i, got, wantPairs[i])
+		}
+	}
+	if p := bob.Pairs[0]; p.Audio.Start() != 30*time.Second || p.Video.End() != 90*time.Second {
+		t.Errorf("got bob pair %+v %+v", *p.Audio, *p.Video)
+	}
+}
+
+func TestAlignUsesOffsets(t *testing.T) {
+	// Created in the same second, their media starts apart on Twilio's clock.
+	recs := []recording.RecordingInstance{
+		rec("RT1", "PA1", recording.TypeAudio, 3, 10),
+		rec("RT2", "PA1", recording.TypeVideo, 3, 10),
+		rec("RT3", "PA2", recording.TypeAudio, 3, 10),
+	}
+	recs[0].Offset = 5002500
+	recs[1].Offset = 5000000
+	recs[2].Offset = 5010250
+
+	a := Align("RM1", roomStart, recs)
+	if a.EpochOffsetMs != 4997000 {
+		t.Errorf("got offset %d", a.EpochOffsetMs)
+	}
+	pair := a.Participants[0].Pairs[0]
+	if pair.Video.StartMs != 3000 || pair.Audio.StartMs != 5500 {
+		t.Errorf("got video at %d and audio at %d", pair.Video.StartMs, pair.Audio.StartMs)
+	}
+	if got := a.Participants[1].Pairs[0].Audio; got.StartMs != 13250 || got.EndMs != 23250 {
+		t.Errorf("got second participant at %d-%d", got.StartMs, got.EndMs)
+	}
+}
+
+func TestAlignMixesOffsets(t *testing.T) {
+	recs := []recording.RecordingInstance{
+		rec("RT1", "PA1", recording.TypeAudio, 10, 20),
+		rec("RT2", "PA1", recording.TypeVideo, 10, 20),
+		rec("RT3", "PA2", recording.TypeAudio, 12, 5),
+	}
+	recs[1].Offset += 400
+	recs[2].Offset = 0
+
+	a := Align("RM1", roomStart, recs)
+	if a.EpochOffsetMs != 1000000 {
+		t.Errorf("got offset %d", a.EpochOffsetMs)
+	}
+	pair := a.Participants[0].Pairs[0]
+	if pair.Audio.StartMs != 10000 || pair.Video.StartMs != 10400 {
+		t.Errorf("got audio at %d and video at %d", pair.Audio.StartMs, pair.Video.StartMs)
+	}
+	if got := a.Participants[1].Pairs[0].Audio; got.StartMs != 12000 || got.EndMs != 17000 {
+		t.Errorf("got the recording without an offset at %d-%d", got.StartMs, got.EndMs)
+	}
+	if want := []Gap{{0, 10000}}; !reflect.DeepEqual(a.Gaps, want) {
+		t.Errorf("got gaps %v, want %v", a.Gaps, want)
+	}
+}
+
+func TestAlignWithoutOffsets(t *testing.T) {
+	recs := []recording.RecordingInstance{
+		rec("RT1", "PA1", recording.TypeAudio, 30, 10),
+		rec("RT2", "PA1", recording.TypeVideo, 32, 8),
+	}
+	for i := range recs {
+		recs[i].Offset = 0
+	}
+	a := Align("RM1", time.Time{}, recs)
+	if !a.RoomStart.Equal(roomStart.Add(30 * time.Second)) {
+		t.Errorf("got room start %v", a.RoomStart)
+	}
+	if len(a.Gaps) != 0 || a.DurationMs != 10000 || a.EpochOffsetMs != 0 {
+		t.Errorf("got gaps %v, duration %d and offset %d", a.Gaps, a.DurationMs, a.EpochOffsetMs)
+	}
+}
+
+func TestAlignmentJSON(t *testing.T) {
+	a := Align("RM1", roomStart, []recording.RecordingInstance{
+		rec("RT1", "PA1", recording.TypeAudio, 1, 2),
+	})
+	bb, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"room_sid":"RM1","room_start":"2021-05-18T10:00:00Z","epoch_offset_ms":1000000,"duration_ms":3000,` +
+		`"participants":[{"participant_sid":"PA1","pairs":[{"audio":{"recording_sid":"RT1","source_sid":"MTRT1",` +
+		`"track_name":"PA1-audio","type":"audio","codec":"","container":"","start_ms":1000,"end_ms":3000},"video":null}],` +
+		`"gaps":[]}],"gaps":[{"start_ms":0,"end_ms":1000}]}`
+	if string(bb) != want {
+		t.Errorf("got\n%s\nwant\n%s", bb, want)
+	}
+}
@@ -23,10 +23,18 @@ func (url VideoUrl) WithCompositionHooksURIAndPathParam(compositionHooksSid stri
 	return string(url) + "/v1/CompositionHooks" + pathParam
 }
 
+func (url VideoUrl) WithCompositionHooksURIAndQueryParameters(values url.Values) string {
+	return url.WithCompositionHooksURI() + "?" + values.Encode()
+}
+
 func (url VideoUrl) WithCompositionURI() string {
 	return string(url) + "/v1/Compositions"
 }
 
+func (url VideoUrl) WithCompositionURIAndPathParam(compositionSid string) string {
+	return url.WithCompositionURI() + "/" + compositionSid
+}
+
 func (url VideoUrl) WithCompositionURIMedia(compositionSid string) string {
 	return url.WithCompositionURI() + "/" + compositionSid + "/Media"
 }
@@ -39,12 +47,29 @@ func (url VideoUrl) WithRoomsURI() string {
 	return string(url) + "/v1/Rooms"
 }
 
+// WithRoomsURIAndPathParam takes the room's SID or unique name, which is escaped.
+func (url VideoUrl) WithRoomsURIAndPathParam(roomSid string) string {
+	return url.WithRoomsURI() + "/" + pathParam(roomSid)
+}
+
+func (url VideoUrl) WithRecordingRulesURI(roomSid string) string {
+	return url.WithRoomsURIAndPathParam(roomSid) + "/RecordingRules"
+}
+
 func (url VideoUrl) WithRoomsURIAndQueryParameters(values url.Values) string {
 	return url.WithRoomsURI() + "?" + values.Encode()
 }
 
 func (url VideoUrl) WithRecordingsURI() string {
-	return string(BaseUrl) + "/v1/Recordings"
+	return string(url) + "/v1/Recordings"
+}
+
+func (url VideoUrl) WithRecordingsURIAndPathParam(recordingSid string) string {
+	return url.WithRecordingsURI() + "/" + recordingSid
+}
+
+func (url VideoUrl) WithRoomRecordingsURI(roomSid string) string {
+	return url.WithRoomsURIAndPathParam(roomSid) + "/Recordings"
 }
 
 func (url VideoUrl) WithRecordingsURIAndQueryParam(values url.Values) string {
@@ -52,6 +77,44 @@ func (url VideoUrl) WithRecordingsURIAndQueryParam(values url.Values) string {
 }
 
 func (url VideoUrl) WithRoomParticipantsURI(roomSid string) string {
-	return fmt.Sprintf("%s/%s/Participants", url.WithRoomsURI(), roomSid)
+	return fmt.Sprintf("%s/Participants", url.WithRoomsURIAndPathParam(roomSid))
 }
 
+// WithRoomParticipantsURIAndPathParam takes the participant's SID or identity, which is escaped.
+func (url VideoUrl) WithRoomParticipantsURIAndPathParam(roomSid, participantSid string) string {
+	return url.WithRoomParticipantsURI(roomSid) + "/" + pathParam(participantSid)
+}
+
+func (url VideoUrl) WithPublishedTracksURI(roomSid, participantSid string) string {
+	return url.WithRoomParticipantsURIAndPathParam(roomSid, participantSid) + "/PublishedTracks"
+}
+
+func (url VideoUrl) WithSubscribedTracksURI(roomSid, participantSid string) string {
+	return url.WithRoomParticipantsURIAndPathParam(roomSid, participantSid) + "/SubscribedTracks"
+}
+
+func (url VideoUrl) WithPublishedTracksURIAndPathParam(roomSid, participantSid, trackSid string) string {
+	return url.WithPublishedTracksURI(roomSid, participantSid) + "/" + pathParam(trackSid)
+}
+
+func (url VideoUrl) WithSubscribedTracksURIAndPathParam(roomSid, participantSid, trackSid string) string {
+	return url.WithSubscribedTracksURI(roomSid, participantSid) + "/" + pathParam(trackSid)
+}
+
+func (url VideoUrl) WithSubscribeRulesURI(roomSid, participantSid string) string {
+	return url.WithRoomParticipantsURIAndPathParam(roomSid, participantSid) + "/SubscribeRules"
+}
+
+func (url VideoUrl) WithAnonymizeURI(roomSid, participantSid string) string {
+	return url.WithRoomParticipantsURIAndPathParam(roomSid, participantSid) + "/Anonymize"
+}
+
+func (url VideoUrl) WithRoomParticipantsURIAndQueryParameters(roomSid string, values url.Values) string {
+	return url.WithRoomParticipantsURI(roomSid) + "?" + values.Encode()
+}
+
+// pathParam escapes a value taking a whole segment of the path,
+// such as names and identities, which may hold a slash.
+func pathParam(s string) string {
+	return url.PathEscape(s)
+}
@@ -0,0 +1,15 @@
+package video
+
+import "testing"
+
+func TestPathParamsAreEscaped(t *testing.T) {
+	got := BaseUrl.WithSubscribedTracksURIAndPathParam("class/1", "alice/bob", "MT1")
+	want := "https://video.twilio.com/v1/Rooms/class%2F1/Participants/alice%2Fbob/SubscribedTracks/MT1"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got := BaseUrl.WithSubscribeRulesURI("RM1", "alice/bob"); got !=
+		"https://video.twilio.com/v1/Rooms/RM1/Participants/alice%2Fbob/SubscribeRules" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,152 @@
+package video
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MarshalJSON encodes the layout as Twilio's video_layout object,
+// with the regions in the layout's order.
+// Resolution is not part of it, it is sent as its own parameter.
+func (l VideoLayout) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, r := range l.regions {
+		if r == nil {
+			return nil, errors.New("Error, the region is nil.")
+		}
+		if r.Prop == nil {
+			return nil, &RegionError{Region: r.Name, Err: errors.New("region must have properties")}
+		}
+
+		name, err := json.Marshal(r.Name)
+		if err != nil {
+			return nil, err
+		}
+		prop, err := json.Marshal(r.Prop)
+		if err != nil {
+			return nil, &RegionError{Region: r.Name, Err: err}
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(prop)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes Twilio's video_layout object, keeping the regions
+// in the order they appear. The resolution is left as is, since it is not part of it.
+// The regions are not validated, use Validate for that.
+func (l *VideoLayout) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("Error, video layout must be an object, got %v.", tok)
+	}
+
+	var regions []*Region
+	seen := map[string]bool{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name := tok.(string) // Object keys are always strings.
+		if seen[name] {
+			return &RegionError{Region: name, Err: errors.New("name is duplicated")}
+		}
+		seen[name] = true
+
+		prop := &RegionProp{}
+		if err := dec.Decode(prop); err != nil {
+			return &RegionError{Region: name, Err: err}
+		}
+		regions = append(regions, &Region{Name: name, Prop: prop})
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	l.regions = regions
+	return nil
+}
+
+// regionPropFields avoids the recursion into RegionProp's own JSON methods.
+type regionPropFields RegionProp
+
+// MarshalJSON encodes the properties Twilio knows,
+// along with any unknown ones the properties were decoded with.
+func (p RegionProp) MarshalJSON() ([]byte, error) {
+	known, err := json.Marshal(regionPropFields(p))
+	if err != nil {
+		return nil, err
+	}
+	if len(p.extra) == 0 {
+		return known, nil
+	}
+
+	merged := make(map[string]json.RawMessage, len(p.extra))
+	for k, v := range p.extra {
+		merged[k] = v
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(known, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON decodes the properties and keeps the ones unknown to this package.
+// A value of the wrong type or out of its type's range is an error.
+func (p *RegionProp) UnmarshalJSON(data []byte) error {
+	var fields regionPropFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for _, k := range regionPropKeys {
+		delete(all, k)
+	}
+	if len(all) > 0 {
+		fields.extra = all
+	} else {
+		fields.extra = nil
+	}
+
+	*p = RegionProp(fields)
+	return nil
+}
+
+var regionPropKeys = []string{
+	"x_pos",
+	"y_pos",
+	"z_pos",
+	"width",
+	"height",
+	"max_columns",
+	"max_rows",
+	"cells_excluded",
+	"reuse",
+	"video_sources",
+	"video_sources_excluded",
+}
@@ -0,0 +1,83 @@
+package video
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const twilioLayout = `{"strip":{"x_pos":8,"y_pos":360,"width":624,"height":112,"max_columns":4,"max_rows":1,` +
+	`"cells_excluded":[2,3],"reuse":"show_oldest","video_sources":["*"],"video_sources_excluded":["teacher"]},` +
+	`"speaker":{"z_pos":-3,"video_sources":["teacher"],"future_property":{"a":1}}}`
+
+func TestVideoLayoutJSONRoundTrip(t *testing.T) {
+	var l VideoLayout
+	if err := json.Unmarshal([]byte(twilioLayout), &l); err != nil {
+		t.Fatal(err)
+	}
+
+	regs := l.GetRegions()
+	if len(regs) != 2 || regs[0].Name != "strip" || regs[1].Name != "speaker" {
+		t.Fatalf("regions must keep the document order, got %v", regs)
+	}
+	if p := regs[0].Prop; *p.YPos != 360 || p.CellsExcluded[1] != 3 || *regs[1].Prop.ZPos != -3 {
+		t.Errorf("unexpected properties %+v", p)
+	}
+
+	out, err := json.Marshal(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got interface{}
+	json.Unmarshal([]byte(twilioLayout), &want)
+	json.Unmarshal(out, &got)
+	wantB, _ := json.Marshal(want)
+	gotB, _ := json.Marshal(got)
+	if string(wantB) != string(gotB) {
+		t.Errorf("round trip lost data:\n got %s\nwant %s", gotB, wantB)
+	}
+	if !strings.HasPrefix(string(out), `{"strip":`) {
+		t.Errorf("regions must be encoded in order, got %s", out)
+	}
+}
+
+func TestVideoLayoutJSONErrors(t *testing.T) {
+	invalid := []string{
+		`[]`,
+		`{"grid":"not an object"}`,
+		`{"grid":{"cells_excluded":[1.5]}}`,
+		`{"grid":{"cells_excluded":["1"]}}`,
+		`{"grid":{"x_pos":-1}}`,
+		`{"grid":{"z_pos":40000}}`,
+		`{"grid":{"video_sources":"*"}}`,
+		`{"grid":{},"grid":{}}`,
+	}
+	for _, s := range invalid {
+		var l VideoLayout
+		if err := json.Unmarshal([]byte(s), &l); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestNewFrom(t *testing.T) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(twilioLayout), &obj); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewFrom(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A map has no order, so the regions are sorted by name.
+	if regs := l.GetRegions(); regs[0].Name != "speaker" || regs[1].Name != "strip" {
+		t.Errorf("got %s, %s", regs[0].Name, regs[1].Name)
+	}
+
+	if _, err := NewFrom(map[string]interface{}{"grid": 1}); err == nil {
+		t.Error("expected error for a region that is not an object")
+	}
+	if _, err := NewFrom(map[string]interface{}{"grid": map[string]interface{}{}}); err == nil {
+		t.Error("expected validation error for a region without video sources")
+	}
+}
@@ -0,0 +1,72 @@
+package video
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddRegionReportsAllViolations(t *testing.T) {
+	l, err := NewVideoLayout(VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddRegion(&Region{Name: "main", Prop: &RegionProp{VideoSources: []string{"*"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	x := uint16(630)
+	err = l.AddRegion(
+		&Region{Name: "corner", Prop: &RegionProp{XPos: &x, VideoSources: []string{" "}}},
+		&Region{Name: "main", Prop: &RegionProp{VideoSources: []string{"*"}, VideoSourcesExcluded: []string{""}}},
+	)
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+	want := map[string]int{"corner": 2, "main": 2}
+	got := map[string]int{}
+	for _, e := range errs {
+		var regErr *RegionError
+		if !errors.As(e, &regErr) {
+			t.Fatalf("error %v does not name its region", e)
+		}
+		got[regErr.Region]++
+	}
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("region %q: got %d violations, want %d: %v", name, got[name], n, errs)
+		}
+	}
+	if len(l.GetRegions()) != 1 {
+		t.Errorf("invalid regions must not be added, got %d regions", len(l.GetRegions()))
+	}
+}
+
+func TestValidateSourcePattern(t *testing.T) {
+	valid := []string{"*", "student*", "*Team", "RT1234", "teacher cam", "student?", "[ab]*", "student**"}
+	for _, src := range valid {
+		if err := validateSourcePattern(src); err != nil {
+			t.Errorf("%q: %v", src, err)
+		}
+	}
+	invalid := []string{"", " "}
+	for _, src := range invalid {
+		if err := validateSourcePattern(src); err == nil {
+			t.Errorf("%q: expected error", src)
+		}
+	}
+}
+
+func TestLiteralQuestionMarkSource(t *testing.T) {
+	l, err := NewVideoLayout(VGA())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddRegion(&Region{Name: "main", Prop: &RegionProp{VideoSources: []string{"who?*"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !MatchSource("who?*", "who?-cam") || MatchSource("who?*", "whom-cam") {
+		t.Error("? must be matched literally")
+	}
+}